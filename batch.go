@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"challenge-calculator/calculate"
+	"challenge-calculator/logger"
+
+	"github.com/shopspring/decimal"
+)
+
+// sharedFlags are the top-level flags that subcommands accept as well.
+var sharedFlags = []string{
+	"log", "log-format", "log-file",
+	"delimiter", "delimiter-regex", "locale", "formats", "currencies", "money", "currency", "rates", "rational", "precision", "rounding", "fixed", "round-terms", "allow-negatives", "negatives",
+	"min-number", "max-number", "min-exclusive", "max-exclusive", "min-action", "max-action",
+	"op", "division-precision", "mode", "strict", "unescape", "workers",
+}
+
+// addSharedFlags registers sharedFlags on fs. They share their values with
+// the top-level flags, so the rest of the program sees them as usual.
+func addSharedFlags(fs *flag.FlagSet) {
+	for _, name := range sharedFlags {
+		f := flag.Lookup(name)
+		fs.Var(f.Value, name, f.Usage)
+	}
+}
+
+// batchRecord is the outcome of one record in a batch.
+type batchRecord struct {
+	Record   int              `json:"record"`
+	Input    string           `json:"input"`
+	Formula  string           `json:"formula,omitempty"`
+	Sum      *decimal.Decimal `json:"sum,omitempty"`
+	Fraction *big.Rat         `json:"fraction,omitempty"`
+	Currency string           `json:"currency,omitempty"`
+	Error    string           `json:"error,omitempty"`
+	Warnings []string         `json:"warnings,omitempty"`
+	Streamed bool             `json:"streamed,omitempty"`
+}
+
+// recordWriter writes batch records in one output format.
+type recordWriter interface {
+	Write(record batchRecord) error
+	Flush() error
+}
+
+func newRecordWriter(format string, w io.Writer) (recordWriter, error) {
+	switch format {
+	case "text":
+		return &textRecordWriter{w: w}, nil
+	case "jsonl":
+		return &jsonlRecordWriter{encoder: json.NewEncoder(w)}, nil
+	case "csv":
+		return &csvRecordWriter{w: csv.NewWriter(w)}, nil
+	}
+	return nil, fmt.Errorf("unknown -format: %q", format)
+}
+
+// textRecordWriter writes one line per record: the formula, or the error.
+// Each warning follows on a line of its own.
+type textRecordWriter struct {
+	w io.Writer
+}
+
+func (t *textRecordWriter) Write(record batchRecord) error {
+	var err error
+	switch {
+	case record.Error != "":
+		_, err = fmt.Fprintf(t.w, "%d: error: %s\n", record.Record, record.Error)
+	case record.Streamed:
+		_, err = fmt.Fprintf(t.w, "%d: (line too long to show) = %s\n", record.Record, record.Sum)
+	default:
+		_, err = fmt.Fprintf(t.w, "%d: %s\n", record.Record, record.Formula)
+	}
+	for _, warning := range record.Warnings {
+		if err != nil {
+			break
+		}
+		_, err = fmt.Fprintf(t.w, "%d: warning: %s\n", record.Record, warning)
+	}
+	return err
+}
+
+func (t *textRecordWriter) Flush() error {
+	return nil
+}
+
+type jsonlRecordWriter struct {
+	encoder *json.Encoder
+}
+
+func (j *jsonlRecordWriter) Write(record batchRecord) error {
+	return j.encoder.Encode(record)
+}
+
+func (j *jsonlRecordWriter) Flush() error {
+	return nil
+}
+
+// csvRecordWriter writes a header row before the first record. Warnings
+// share one field, a line each.
+type csvRecordWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (c *csvRecordWriter) Write(record batchRecord) error {
+	if !c.headerWritten {
+		if err := c.w.Write([]string{"record", "input", "formula", "sum", "error", "warnings"}); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+
+	sum := ""
+	if record.Sum != nil {
+		sum = record.Sum.String()
+	}
+	return c.w.Write([]string{strconv.Itoa(record.Record), record.Input, record.Formula, sum, record.Error, strings.Join(record.Warnings, "\n")})
+}
+
+func (c *csvRecordWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// batch calculates every record of the named files, or of stdin for "-",
+// and writes one output record for each. It returns the exit status: 1 if
+// any record failed or any file could not be read.
+func batch(args []string) int {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	records := fs.String("records", "line", "Set what makes up a record (line, block); blocks are separated by blank lines")
+	format := fs.String("format", "text", "Set the output format (text, jsonl, csv)")
+	addSharedFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: challenge-calculator batch [flags] FILE... (use - for stdin)")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	closeLog, err := setupLogging(*logLevel, *logFormat, *logFile)
+	if err != nil {
+		logger.Error(err.Error())
+		return 2
+	}
+	defer closeLog()
+
+	if *records != "line" && *records != "block" {
+		logger.Error(fmt.Sprintf("unknown -records: %q", *records))
+		return 2
+	}
+	// Block records are read the same way as multiline input.
+	*multiline = *records == "block"
+
+	defaultOp, err := calculate.ParseOperation(*operation)
+	if err != nil {
+		logger.Error(err.Error())
+		return 2
+	}
+	calculator, err := newCalculator()
+	if err != nil {
+		logger.Error(err.Error())
+		return 2
+	}
+	if err := checkDelimiters(calculator); err != nil {
+		logger.Error(err.Error())
+		return 2
+	}
+	writer, err := newRecordWriter(*format, os.Stdout)
+	if err != nil {
+		logger.Error(err.Error())
+		return 2
+	}
+
+	b := &batchRun{calculator: calculator, defaultOp: defaultOp, writer: writer}
+	status := 0
+	for _, name := range fs.Args() {
+		if err := b.runFile(name); err != nil {
+			logger.UserErr(fmt.Sprintf("Error processing %s: %v", name, err))
+			status = 1
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		logger.Error(fmt.Sprintf("Error writing output: %v", err))
+		return 1
+	}
+
+	logger.UserErr(fmt.Sprintf("Processed %d records: %d succeeded, %d failed", b.successes+b.failures, b.successes, b.failures))
+	if b.failures > 0 {
+		status = 1
+	}
+	return status
+}
+
+// batchRun numbers records across every file of a batch.
+type batchRun struct {
+	calculator *calculate.Calculator
+	defaultOp  calculate.Operation
+	writer     recordWriter
+
+	successes int
+	failures  int
+}
+
+func (b *batchRun) runFile(name string) error {
+	if name == "-" {
+		return b.run(os.Stdin)
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return b.run(file)
+}
+
+// run calculates each record of r. Blank lines are skipped rather than
+// treated as empty calculations. Lines too long to hold in memory are
+// streamed, and their input and formula are left out of the output.
+func (b *batchRun) run(r io.Reader) error {
+	reader := newRecordReader(r, *multiline, "", canStream())
+	for {
+		rec, ok := reader.Next()
+		if !ok {
+			break
+		}
+		if rec.stream == nil && !*multiline && strings.TrimSpace(rec.text) == "" {
+			continue
+		}
+
+		record := batchRecord{Record: b.successes + b.failures + 1, Input: rec.text, Streamed: rec.stream != nil}
+		var sum decimal.Decimal
+		var err error
+		if rec.stream != nil {
+			var result *calculate.StreamResult
+			if result, err = calculateStream(b.calculator, b.defaultOp, rec.stream, io.Discard); err == nil {
+				sum = result.Total
+				record.Fraction = result.Fraction
+				record.Currency = result.Currency
+				record.Warnings = result.Warnings
+			}
+		} else {
+			var result *calculate.Result
+			if result, err = calculateLine(b.calculator, b.defaultOp, rec.text); err == nil {
+				record.Formula = result.String()
+				sum = result.Total
+				record.Fraction = result.Fraction
+				record.Currency = result.Currency
+				record.Warnings = result.Warnings
+			}
+		}
+
+		if err != nil {
+			b.failures++
+			record.Error = err.Error()
+		} else {
+			b.successes++
+			record.Sum = &sum
+		}
+		if err := b.writer.Write(record); err != nil {
+			return err
+		}
+	}
+	return reader.Err()
+}
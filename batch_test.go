@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchRun(t *testing.T) {
+	*mode = "list"
+	defer func() { *multiline = false }()
+
+	tests := []struct {
+		name      string
+		format    string
+		multiline bool
+		input     string
+		expected  string
+	}{
+		{
+			name:     "text lines",
+			format:   "text",
+			input:    "1,2\n\n-1\n//;\\n3;4\n",
+			expected: "1: 1+2 = 3\n2: error: invalid input: negative numbers found: -1\n3: 3+4 = 7\n",
+		},
+		{
+			name:     "text with warnings",
+			format:   "text",
+			input:    "1,x,y\n",
+			expected: "1: 1+0+0 = 1\n1: warning: invalid token \"x\" at index 1 (offset 2): not a number, treated as 0\n1: warning: invalid token \"y\" at index 2 (offset 4): not a number, treated as 0\n",
+		},
+		{
+			name:     "json lines",
+			format:   "jsonl",
+			input:    "1,2\n-1\n",
+			expected: `{"record":1,"input":"1,2","formula":"1+2 = 3","sum":"3"}` + "\n" + `{"record":2,"input":"-1","error":"invalid input: negative numbers found: -1"}` + "\n",
+		},
+		{
+			name:     "json lines with warnings",
+			format:   "jsonl",
+			input:    "1,x\n",
+			expected: `{"record":1,"input":"1,x","formula":"1+0 = 1","sum":"1","warnings":["invalid token \"x\" at index 1 (offset 2): not a number, treated as 0"]}` + "\n",
+		},
+		{
+			name:      "csv blocks",
+			format:    "csv",
+			multiline: true,
+			input:     "//;\n1;2\n\n\n5,6\n",
+			expected:  "record,input,formula,sum,error,warnings\n1,\"//;\n1;2\",1+2 = 3,3,,\n2,\"5,6\",5+6 = 11,11,,\n",
+		},
+		{
+			name:     "csv with warnings",
+			format:   "csv",
+			input:    "1,x,y\n",
+			expected: "record,input,formula,sum,error,warnings\n1,\"1,x,y\",1+0+0 = 1,1,,\"invalid token \"\"x\"\" at index 1 (offset 2): not a number, treated as 0\ninvalid token \"\"y\"\" at index 2 (offset 4): not a number, treated as 0\"\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			*multiline = test.multiline
+			calculator, err := newCalculator()
+			assert.NoError(t, err)
+
+			var out bytes.Buffer
+			writer, err := newRecordWriter(test.format, &out)
+			assert.NoError(t, err)
+			b := &batchRun{calculator: calculator, defaultOp: "add", writer: writer}
+
+			assert.NoError(t, b.run(strings.NewReader(test.input)))
+			assert.NoError(t, writer.Flush())
+			assert.Equal(t, test.expected, out.String())
+		})
+	}
+}
+
+func TestBatchNumbersRecordsAcrossInputs(t *testing.T) {
+	*mode = "list"
+	calculator, err := newCalculator()
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	writer, _ := newRecordWriter("text", &out)
+	b := &batchRun{calculator: calculator, defaultOp: "add", writer: writer}
+	assert.NoError(t, b.run(strings.NewReader("1\n2\n")))
+	assert.NoError(t, b.run(strings.NewReader("3\n")))
+
+	assert.Equal(t, "1: 1 = 1\n2: 2 = 2\n3: 3 = 3\n", out.String())
+	assert.Equal(t, 3, b.successes)
+}
+
+func TestBatchChecksDelimiters(t *testing.T) {
+	defer resetFlags()
+
+	missing := filepath.Join(t.TempDir(), "missing.txt")
+	assert.Equal(t, 1, batch([]string{missing}))
+	assert.Equal(t, 2, batch([]string{"-delimiter", "-", missing}))
+}
+
+func TestNewRecordWriter(t *testing.T) {
+	_, err := newRecordWriter("xml", &bytes.Buffer{})
+	assert.EqualError(t, err, `unknown -format: "xml"`)
+}
+
+func TestBatchStreamsLongLines(t *testing.T) {
+	*mode = "list"
+	defer func(length int) { maxLineLength = length }(maxLineLength)
+	maxLineLength = 16
+
+	calculator, err := newCalculator()
+	assert.NoError(t, err)
+	var out bytes.Buffer
+	writer, _ := newRecordWriter("jsonl", &out)
+	b := &batchRun{calculator: calculator, defaultOp: "add", writer: writer}
+
+	assert.NoError(t, b.run(strings.NewReader(strings.Repeat("1,", 20)+"1\n2,3\n")))
+	assert.Equal(t, `{"record":1,"input":"","sum":"21","streamed":true}`+"\n"+`{"record":2,"input":"2,3","formula":"2+3 = 5","sum":"5"}`+"\n", out.String())
+}
@@ -2,7 +2,9 @@ package calculate
 
 import (
 	"fmt"
-	"strings"
+	"math/big"
+	"regexp"
+	"time"
 
 	"challenge-calculator/logger"
 	"challenge-calculator/validate"
@@ -10,45 +12,352 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+const defaultDivisionPrecision int32 = 16
+
+// maxValidNumber is the package-level limit used by the free functions.
 var maxValidNumber = decimal.NewFromInt(1000)
 
+// defaultMax is the maximum a Calculator starts with.
+func defaultMax() *Bound {
+	return &Bound{Value: decimal.NewFromInt(1000), Action: BoundExclude}
+}
+
+// Calculator holds its own validation settings and limits, so one value can
+// be shared between goroutines and different callers can use different
+// settings side by side.
+type Calculator struct {
+	validator         *validate.Validator
+	validatorOpts     []validate.Option
+	valueRange        Range
+	divisionPrecision int32
+	workers           int
+	money             *Money
+	rational          RationalFormat
+	precision         *Precision
+}
+
+type Option func(*Calculator)
+
+// WithDefaultDelimiter sets the delimiter accepted alongside ",".
+func WithDefaultDelimiter(delimiter string) Option {
+	return func(c *Calculator) {
+		c.validatorOpts = append(c.validatorOpts, validate.WithDefaultDelimiter(delimiter))
+	}
+}
+
+// WithDelimiters replaces the full default delimiter set.
+func WithDelimiters(delimiters ...string) Option {
+	return func(c *Calculator) {
+		c.validatorOpts = append(c.validatorOpts, validate.WithDelimiters(delimiters...))
+	}
+}
+
+// WithDelimiterPattern adds a regular expression that matches delimiters.
+func WithDelimiterPattern(pattern *regexp.Regexp) Option {
+	return func(c *Calculator) {
+		c.validatorOpts = append(c.validatorOpts, validate.WithDelimiterPattern(pattern))
+	}
+}
+
+func WithAllowNegatives(allow bool) Option {
+	return func(c *Calculator) {
+		c.validatorOpts = append(c.validatorOpts, validate.WithAllowNegatives(allow))
+	}
+}
+
+// WithNegatives sets how negative numbers are treated. See
+// validate.NegativesPolicy.
+func WithNegatives(policy validate.NegativesPolicy) Option {
+	return func(c *Calculator) {
+		c.validatorOpts = append(c.validatorOpts, validate.WithNegatives(policy))
+	}
+}
+
+// WithStrict rejects input containing tokens that are not numbers instead
+// of treating them as zero.
+func WithStrict(strict bool) Option {
+	return func(c *Calculator) {
+		c.validatorOpts = append(c.validatorOpts, validate.WithStrict(strict))
+	}
+}
+
+// WithMaxExponent rejects numbers whose exponent is beyond max in either
+// direction. See validate.WithMaxExponent.
+func WithMaxExponent(max int32) Option {
+	return func(c *Calculator) {
+		c.validatorOpts = append(c.validatorOpts, validate.WithMaxExponent(max))
+	}
+}
+
+// WithLocale parses numbers written for locale. See validate.Locale.
+func WithLocale(locale validate.Locale) Option {
+	return func(c *Calculator) {
+		c.validatorOpts = append(c.validatorOpts, validate.WithLocale(locale))
+	}
+}
+
+// WithFormats accepts numbers written in the given formats. See
+// validate.Format.
+func WithFormats(formats ...validate.Format) Option {
+	return func(c *Calculator) {
+		c.validatorOpts = append(c.validatorOpts, validate.WithFormats(formats...))
+	}
+}
+
+// WithMaxValidNumber sets the largest number included in calculations.
+// Larger numbers are treated as zero.
+func WithMaxValidNumber(max int64) Option {
+	return WithMax(&Bound{Value: decimal.NewFromInt(max), Action: BoundExclude})
+}
+
+// WithRange replaces both bounds on the terms included in calculations.
+func WithRange(r Range) Option {
+	return func(c *Calculator) {
+		c.valueRange = r
+	}
+}
+
+// WithMin sets the lower bound on terms. nil removes it.
+func WithMin(min *Bound) Option {
+	return func(c *Calculator) {
+		c.valueRange.Min = min
+	}
+}
+
+// WithMax sets the upper bound on terms. nil removes it.
+func WithMax(max *Bound) Option {
+	return func(c *Calculator) {
+		c.valueRange.Max = max
+	}
+}
+
+// WithDivisionPrecision sets the number of decimal places kept when dividing.
+func WithDivisionPrecision(places int32) Option {
+	return func(c *Calculator) {
+		c.divisionPrecision = places
+	}
+}
+
+// WithWorkers splits large inputs between up to n goroutines, which parse
+// and combine their share of the terms concurrently. Results are the same as
+// with a single one. Division is always done in order, and the input is
+// split into tokens on one goroutine first.
+func WithWorkers(n int) Option {
+	return func(c *Calculator) {
+		c.workers = n
+		c.validatorOpts = append(c.validatorOpts, validate.WithWorkers(n))
+	}
+}
+
+// New returns a Calculator with the same defaults as the command line:
+// "," and "\n" delimiters, no negatives and a maximum of 1000.
+func New(opts ...Option) *Calculator {
+	c := &Calculator{
+		valueRange:        Range{Max: defaultMax()},
+		divisionPrecision: defaultDivisionPrecision,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.validator = validate.New(c.validatorOpts...)
+	return c
+}
+
+// defaultCalculator builds a Calculator from the package-level settings.
+func defaultCalculator() *Calculator {
+	return &Calculator{
+		validator:         validate.Default(),
+		valueRange:        Range{Max: &Bound{Value: maxValidNumber, Action: BoundExclude}},
+		divisionPrecision: defaultDivisionPrecision,
+	}
+}
+
 func SetMaxValidNumber(max int64) {
 	maxValidNumber = decimal.NewFromInt(max)
 }
 
-func Add(input string) (string, error) {
-	logger.Debug(fmt.Sprintf("Starting addition calculation for input: %s", input))
-	sum := decimal.Zero
-	var formulaParts []string
+func Add(input string) (*Result, error) {
+	return defaultCalculator().Add(input)
+}
+
+func Subtract(input string) (*Result, error) {
+	return defaultCalculator().Subtract(input)
+}
+
+func Multiply(input string) (*Result, error) {
+	return defaultCalculator().Multiply(input)
+}
+
+func Divide(input string) (*Result, error) {
+	return defaultCalculator().Divide(input)
+}
 
-	numbers, err := validate.ValidateInput(input)
+func (c *Calculator) Add(input string) (*Result, error) {
+	return c.Calculate(OpAdd, input)
+}
+
+func (c *Calculator) Subtract(input string) (*Result, error) {
+	return c.Calculate(OpSubtract, input)
+}
+
+func (c *Calculator) Multiply(input string) (*Result, error) {
+	return c.Calculate(OpMultiply, input)
+}
+
+func (c *Calculator) Divide(input string) (*Result, error) {
+	return c.Calculate(OpDivide, input)
+}
+
+// Calculate applies op to the terms of input from left to right. Terms
+// outside the range are handled as their bound says: excluded terms are
+// replaced with the identity for op, so they do not change the result.
+func (c *Calculator) Calculate(op Operation, input string) (*Result, error) {
+	start := time.Now()
+	logger.DebugFields("Starting calculation", logger.Fields{"operation": op, "input": input})
+	result := &Result{Operation: op, Total: decimal.Zero}
+
+	if err := c.valueRange.Validate(); err != nil {
+		return nil, err
+	}
+	if c.money != nil {
+		if err := c.money.validateList(op); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.validateRational(); err != nil {
+		return nil, err
+	}
+	if err := c.validatePrecision(); err != nil {
+		return nil, err
+	}
+	parsed, err := c.validator.Parse(input)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Error validating input: %v", err))
-		return "", err
+		logger.ErrorFields("Error validating input", logger.Fields{"error": err.Error()})
+		return nil, err
 	}
 
-	for _, num := range numbers {
-		if !numberExceedsMaxValue(num) {
-			logger.Debug(fmt.Sprintf("Adding number %s to sum", num.String()))
-			sum = sum.Add(num)
-			formulaParts = append(formulaParts, num.String())
-		} else {
-			logger.Debug(fmt.Sprintf("Number %s is too large, omitting from sum", num.String()))
-			formulaParts = append(formulaParts, "0")
+	result.Warnings = append(result.Warnings, parsed.Warnings...)
+	result.InvalidTokens = parsed.InvalidTokens
+	for _, invalid := range parsed.InvalidTokens {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("invalid token %s, treated as 0", invalid))
+	}
+
+	values := parsed.Values
+	var l *ledger
+	if c.money != nil {
+		l = newLedger(c.money)
+		if values, err = l.convertAll(values, parsed.Currencies, parsed.Offsets, result); err != nil {
+			logger.ErrorFields("Error calculating result", logger.Fields{"error": err.Error()})
+			return nil, err
 		}
 	}
 
-	formula := strings.Join(formulaParts, "+")
-	if len(formulaParts) > 0 {
-		formula += " = " + sum.String()
-	} else {
-		formula = "0 = 0"
+	if err := c.apply(op, values, parsed.Fractions, parsed.Offsets, result); err != nil {
+		logger.ErrorFields("Error calculating result", logger.Fields{"error": err.Error()})
+		return nil, err
+	}
+	if c.rational != "" {
+		if result.Fraction, err = exactTotal(op, result); err != nil {
+			logger.ErrorFields("Error calculating result", logger.Fields{"error": err.Error()})
+			return nil, err
+		}
+		result.rationalFormat = c.rational
+	}
+	if c.rational != "" || c.precision != nil {
+		result.Total = c.roundTotal(result.Total, result.Fraction)
+		result.precision = c.precision
+	}
+	if l != nil {
+		result.Total = l.round(result.Total)
+		result.Currency = l.currency
+	}
+	if err := c.checkTotal(result.Total); err != nil {
+		logger.ErrorFields("Error calculating result", logger.Fields{"error": err.Error()})
+		return nil, err
+	}
+
+	logCompleted(result, input, start)
+	return result, nil
+}
+
+// CheckDelimiters reports problems with the configured delimiters. See
+// validate.Validator.CheckDelimiters.
+func (c *Calculator) CheckDelimiters() ([]string, error) {
+	return c.validator.CheckDelimiters()
+}
+
+// apply fills in the terms and total of result from values, found at
+// offsets in the input, one chunk per worker when there are enough of them.
+// fractions holds the exact value of terms written as fractions, and may be
+// nil.
+func (c *Calculator) apply(op Operation, values []decimal.Decimal, fractions []*big.Rat, offsets []int, result *Result) error {
+	result.Terms = make([]Term, len(values))
+	if c.workers <= 1 || op == OpDivide || len(values) < 2*minParallelTerms {
+		chunk, err := c.fold(op, values, fractions, offsets, 0, result.Terms)
+		if err != nil {
+			return err
+		}
+		result.Total, result.Kept, result.Dropped = chunk.total, chunk.kept, chunk.dropped
+		result.Warnings = append(result.Warnings, chunk.warnings...)
+		return nil
 	}
+	return c.applyParallel(op, values, fractions, offsets, result)
+}
+
+// foldedChunk is the outcome of folding a run of terms.
+type foldedChunk struct {
+	total    decimal.Decimal
+	kept     []Term
+	dropped  []DroppedTerm
+	warnings []string
+}
 
-	logger.Debug(fmt.Sprintf("Calculation completed: %s", formula))
-	return formula, nil
+// fold applies op to values, which start at term index first, from left to
+// right. Terms are written to terms, which has the same length as values.
+func (c *Calculator) fold(op Operation, values []decimal.Decimal, fractions []*big.Rat, offsets []int, first int, terms []Term) (foldedChunk, error) {
+	var chunk foldedChunk
+	for i, num := range values {
+		index := first + i
+		term := Term{Index: index, Value: num}
+		if fractions != nil {
+			term.Fraction = fractions[i]
+		}
+		term = c.roundTerm(term)
+		terms[i] = term
+
+		value, dropped, warning, err := c.applyTerm(term, offsets[i], op.identity())
+		if err != nil {
+			return chunk, err
+		}
+		if dropped == nil {
+			logger.DebugFields("Applying number", logger.Fields{"index": index, "value": term.Value.String()})
+			chunk.kept = append(chunk.kept, term)
+		} else {
+			logger.DebugFields("Replacing number", logger.Fields{"index": index, "value": term.Value.String(), "replacement": value.String()})
+			chunk.dropped = append(chunk.dropped, *dropped)
+		}
+		if warning != "" {
+			chunk.warnings = append(chunk.warnings, warning)
+		}
+
+		if i == 0 {
+			chunk.total = value
+			continue
+		}
+		chunk.total, err = op.apply(chunk.total, value, c.divisionPrecision)
+		if err != nil {
+			return chunk, fmt.Errorf("%w: term %d is zero", err, index+1)
+		}
+	}
+	return chunk, nil
 }
 
-func numberExceedsMaxValue(number decimal.Decimal) bool {
-	return number.GreaterThan(maxValidNumber)
+func logCompleted(result *Result, input string, start time.Time) {
+	logger.DebugFields("Calculation completed", logger.Fields{
+		"formula":        result.String(),
+		"input_len":      len(input),
+		"term_count":     len(result.Terms),
+		"excluded_count": len(result.Dropped),
+		"duration_ms":    float64(time.Since(start).Microseconds()) / 1000,
+	})
 }
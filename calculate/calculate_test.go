@@ -1,7 +1,14 @@
 package calculate
 
 import (
+	"bytes"
+	"challenge-calculator/logger"
 	"challenge-calculator/validate"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/shopspring/decimal"
@@ -165,7 +172,7 @@ func TestAdd(t *testing.T) {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, test.expectedFormula, result)
+				assert.Equal(t, test.expectedFormula, result.String())
 			}
 		})
 	}
@@ -236,7 +243,7 @@ func TestNumberExceedsMaxValue(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			result := numberExceedsMaxValue(test.input)
+			result := New().valueRange.check(test.input) != nil
 			assert.Equal(t, test.expected, result)
 		})
 	}
@@ -306,8 +313,243 @@ func TestMaxNumber(t *testing.T) {
 				assert.EqualError(t, err, test.expectedErr)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, test.expected, result)
+				assert.Equal(t, test.expected, result.String())
 			}
 		})
 	}
 }
+
+func TestCalculatorOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "defaults",
+			opts:     nil,
+			input:    "1\n2,1001",
+			expected: "1+2+0 = 3",
+		},
+		{
+			name:     "alternate default delimiter",
+			opts:     []Option{WithDefaultDelimiter(";")},
+			input:    "1;2,3",
+			expected: "1+2+3 = 6",
+		},
+		{
+			name:     "max valid number",
+			opts:     []Option{WithMaxValidNumber(10)},
+			input:    "5,11,10",
+			expected: "5+0+10 = 15",
+		},
+		{
+			name:     "negatives allowed",
+			opts:     []Option{WithAllowNegatives(true)},
+			input:    "5,-3",
+			expected: "5+-3 = 2",
+		},
+		{
+			name:        "negatives rejected",
+			opts:        nil,
+			input:       "5,-3",
+			expectedErr: "invalid input: negative numbers found: -3",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := New(test.opts...).Add(test.input)
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestCalculatorConcurrentUse(t *testing.T) {
+	calculators := []*Calculator{
+		New(),
+		New(WithAllowNegatives(true), WithMaxValidNumber(5)),
+	}
+	inputs := []struct {
+		input    string
+		expected []string
+	}{
+		{input: "//;\n1;2;3", expected: []string{"1+2+3 = 6", "1+2+3 = 6"}},
+		{input: "//[**]\n4**6", expected: []string{"4+6 = 10", "4+0 = 4"}},
+		{input: "1,2,3,4", expected: []string{"1+2+3+4 = 10", "1+2+3+4 = 10"}},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		for _, test := range inputs {
+			for j, calculator := range calculators {
+				wg.Add(1)
+				go func(calculator *Calculator, input, expected string) {
+					defer wg.Done()
+					result, err := calculator.Add(input)
+					assert.NoError(t, err)
+					assert.Equal(t, expected, result.String())
+				}(calculator, test.input, test.expected[j])
+			}
+		}
+	}
+	wg.Wait()
+}
+
+func TestAddResult(t *testing.T) {
+	result, err := New().Add("1,1001,2.5,abc")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []Term{
+		{Index: 0, Value: decimal.NewFromInt(1)},
+		{Index: 1, Value: decimal.NewFromInt(1001)},
+		{Index: 2, Value: decimal.RequireFromString("2.5")},
+		{Index: 3, Value: decimal.Zero},
+	}, result.Terms)
+	assert.Equal(t, []Term{
+		{Index: 0, Value: decimal.NewFromInt(1)},
+		{Index: 2, Value: decimal.RequireFromString("2.5")},
+		{Index: 3, Value: decimal.Zero},
+	}, result.Kept)
+	assert.Equal(t, []DroppedTerm{
+		{
+			Term:        Term{Index: 1, Value: decimal.NewFromInt(1001)},
+			Reason:      DropReasonExceedsMax,
+			Detail:      "greater than maximum 1000",
+			Replacement: decimal.Zero,
+		},
+	}, result.Dropped)
+	assert.True(t, decimal.RequireFromString("3.5").Equal(result.Total))
+	assert.Equal(t, []string{`invalid token "abc" at index 3 (offset 11): not a number, treated as 0`}, result.Warnings)
+	assert.Equal(t, []*validate.InvalidTokenError{
+		{Token: "abc", Index: 3, Offset: 11, Reason: "not a number"},
+	}, result.InvalidTokens)
+}
+
+func TestStrictMode(t *testing.T) {
+	_, err := New(WithStrict(true)).Add("1,abc,3")
+	assert.EqualError(t, err, `invalid input: invalid tokens found: "abc" at index 1 (offset 2): not a number`)
+
+	result, err := New(WithStrict(true)).Add("1, 2 ,3")
+	assert.NoError(t, err)
+	assert.Equal(t, "1+2+3 = 6", result.String())
+	assert.Empty(t, result.Warnings)
+}
+
+func TestDelimiterChecks(t *testing.T) {
+	_, err := New(WithDefaultDelimiter(".")).Add("1.2")
+	assert.True(t, errors.Is(err, validate.ErrAmbiguousDelimiter))
+
+	result, err := New().Add("//[**][*]\n1**2*3")
+	assert.NoError(t, err)
+	assert.Equal(t, "1+2+3 = 6", result.String())
+	assert.Equal(t, []string{"delimiter \"**\" contains delimiter \"*\"; the longer one is matched first"}, result.Warnings)
+}
+
+func TestFormulaFormatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   *Result
+		expected string
+	}{
+		{
+			name:     "no terms",
+			result:   &Result{},
+			expected: "0 = 0",
+		},
+		{
+			name: "dropped term rendered as zero",
+			result: &Result{
+				Terms: []Term{
+					{Index: 0, Value: decimal.NewFromInt(1)},
+					{Index: 1, Value: decimal.NewFromInt(2000)},
+					{Index: 2, Value: decimal.NewFromInt(2)},
+				},
+				Dropped: []DroppedTerm{{Term: Term{Index: 1, Value: decimal.NewFromInt(2000)}}},
+				Total:   decimal.NewFromInt(3),
+			},
+			expected: "1+0+2 = 3",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, FormulaFormatter{}.Format(test.result))
+		})
+	}
+}
+
+func TestCalculateOperations(t *testing.T) {
+	tests := []struct {
+		name        string
+		op          Operation
+		opts        []Option
+		input       string
+		expected    string
+		expectedErr error
+	}{
+		{name: "subtract", op: OpSubtract, input: "10,2,3", expected: "10-2-3 = 5"},
+		{name: "subtract to negative", op: OpSubtract, input: "1,2", expected: "1-2 = -1"},
+		{name: "subtract with dropped term", op: OpSubtract, input: "10,2000,3", expected: "10-0-3 = 7"},
+		{name: "multiply", op: OpMultiply, input: "2,3,4", expected: "2*3*4 = 24"},
+		{name: "multiply with dropped term", op: OpMultiply, input: "2,2000,4", expected: "2*1*4 = 8"},
+		{name: "multiply with invalid term", op: OpMultiply, input: "2,abc", expected: "2*0 = 0"},
+		{name: "divide", op: OpDivide, input: "10,4", expected: "10/4 = 2.5"},
+		{name: "divide default precision", op: OpDivide, input: "1,3", expected: "1/3 = 0.3333333333333333"},
+		{name: "divide custom precision", op: OpDivide, opts: []Option{WithDivisionPrecision(2)}, input: "2,3", expected: "2/3 = 0.67"},
+		{name: "divide with dropped first term", op: OpDivide, input: "2000,4", expected: "1/4 = 0.25"},
+		{name: "divide by zero", op: OpDivide, input: "1,0", expectedErr: ErrDivideByZero},
+		{name: "divide by missing number", op: OpDivide, input: "1,,2", expectedErr: ErrDivideByZero},
+		{name: "single term", op: OpDivide, input: "7", expected: "7 = 7"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := New(test.opts...).Calculate(test.op, test.input)
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.op, result.Operation)
+				assert.Equal(t, test.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestCalculationCompletedLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	assert.NoError(t, logger.SetFormat(logger.FormatJSON))
+	logger.SetLogLevel(logger.LogLevelDebug)
+	defer func() {
+		logger.SetLogLevel(logger.LogLevelInfo)
+		assert.NoError(t, logger.SetFormat(logger.FormatConsole))
+		logger.SetOutput(os.Stderr)
+	}()
+
+	_, err := New().Add("1,2,3000")
+	assert.NoError(t, err)
+
+	var completed map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &record))
+		if record["message"] == "Calculation completed" {
+			completed = record
+		}
+	}
+
+	assert.NotNil(t, completed)
+	assert.Equal(t, float64(8), completed["input_len"])
+	assert.Equal(t, float64(3), completed["term_count"])
+	assert.Equal(t, float64(1), completed["excluded_count"])
+	assert.Contains(t, completed, "duration_ms")
+}
@@ -0,0 +1,188 @@
+package calculate
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"challenge-calculator/expression"
+	"challenge-calculator/logger"
+
+	"github.com/shopspring/decimal"
+)
+
+func Evaluate(input string) (*Result, error) {
+	return defaultCalculator().Evaluate(input)
+}
+
+// Evaluate parses input as an arithmetic expression such as
+// "(1.5 + 2) * 3 - 4 / 2" and computes it. Literals go through the same
+// negative-number check and range as delimited input, and an excluded
+// literal counts as zero. With WithMoney, the total is rounded to
+// Money.Currency. With WithRational, the expression is evaluated exactly, so
+// "1 / 3 * 3" is 1.
+func (c *Calculator) Evaluate(input string) (*Result, error) {
+	start := time.Now()
+	logger.DebugFields("Starting expression evaluation", logger.Fields{"input": input})
+	result := &Result{Total: decimal.Zero}
+
+	if err := c.valueRange.Validate(); err != nil {
+		return nil, err
+	}
+	if c.money != nil {
+		if err := c.money.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.validateRational(); err != nil {
+		return nil, err
+	}
+	if err := c.validatePrecision(); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(input) == "" {
+		return result, nil
+	}
+
+	node, err := expression.Parse(input)
+	if err != nil {
+		logger.ErrorFields("Error parsing expression", logger.Fields{"error": err.Error()})
+		return nil, err
+	}
+
+	numbers := expression.Numbers(node)
+	literals := make([]decimal.Decimal, len(numbers))
+	offsets := make([]int, len(numbers))
+	for i, number := range numbers {
+		literals[i] = number.Value
+		offsets[i] = number.Offset()
+	}
+	if err := c.validator.CheckNegatives(literals, offsets); err != nil {
+		logger.ErrorFields("Error validating input", logger.Fields{"error": err.Error()})
+		return nil, err
+	}
+
+	// Excluded numbers are zero, whatever the operators around them. values
+	// holds the value each number is calculated with.
+	dropped := make(map[int]*DroppedTerm)
+	values := make(map[int]decimal.Decimal, len(numbers))
+	for _, number := range numbers {
+		term := c.roundTerm(Term{Index: number.Index, Value: number.Value})
+		result.Terms = append(result.Terms, term)
+
+		value, droppedTerm, warning, err := c.applyTerm(term, number.Offset(), decimal.Zero)
+		if err != nil {
+			logger.ErrorFields("Error validating input", logger.Fields{"error": err.Error()})
+			return nil, err
+		}
+		values[number.Index] = value
+		if warning != "" {
+			result.Warnings = append(result.Warnings, warning)
+		}
+		if droppedTerm != nil {
+			logger.DebugFields("Replacing number", logger.Fields{"index": number.Index, "value": term.Value.String(), "replacement": value.String()})
+			result.Dropped = append(result.Dropped, *droppedTerm)
+			dropped[number.Index] = droppedTerm
+			continue
+		}
+		result.Kept = append(result.Kept, term)
+	}
+
+	if c.rational != "" {
+		result.Fraction, err = evaluateExact(input, node, values)
+		result.rationalFormat = c.rational
+	} else {
+		result.Total, err = c.evaluateNode(input, node, values)
+	}
+	if err != nil {
+		logger.ErrorFields("Error calculating result", logger.Fields{"error": err.Error()})
+		return nil, err
+	}
+	if c.rational != "" || c.precision != nil {
+		result.Total = c.roundTotal(result.Total, result.Fraction)
+		result.precision = c.precision
+	}
+	if c.money != nil {
+		l := newLedger(c.money)
+		result.Total = l.round(result.Total)
+		result.Currency = l.currency
+	}
+	if err := c.checkTotal(result.Total); err != nil {
+		logger.ErrorFields("Error calculating result", logger.Fields{"error": err.Error()})
+		return nil, err
+	}
+
+	result.Expression = expression.Format(node, func(number *expression.Number) string {
+		return formatTerm(c.roundTerm(Term{Index: number.Index, Value: number.Value}), dropped[number.Index], c.precision)
+	})
+
+	logCompleted(result, input, start)
+	return result, nil
+}
+
+var binaryOperations = map[expression.TokenKind]Operation{
+	expression.TokenPlus:  OpAdd,
+	expression.TokenMinus: OpSubtract,
+	expression.TokenStar:  OpMultiply,
+	expression.TokenSlash: OpDivide,
+}
+
+// evaluateNode evaluates node, parsed from input, taking the value of each
+// number from values.
+func (c *Calculator) evaluateNode(input string, node expression.Node, values map[int]decimal.Decimal) (decimal.Decimal, error) {
+	switch n := node.(type) {
+	case *expression.Number:
+		return values[n.Index], nil
+	case *expression.Unary:
+		operand, err := c.evaluateNode(input, n.Operand, values)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		return operand.Neg(), nil
+	case *expression.Binary:
+		left, err := c.evaluateNode(input, n.Left, values)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		right, err := c.evaluateNode(input, n.Right, values)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		value, err := binaryOperations[n.Op].apply(left, right, c.divisionPrecision)
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("%w at column %d", err, expression.Column(input, n.Pos))
+		}
+		return value, nil
+	}
+	return decimal.Zero, fmt.Errorf("unsupported expression node %T", node)
+}
+
+// evaluateExact evaluates node as evaluateNode does, without rounding.
+func evaluateExact(input string, node expression.Node, values map[int]decimal.Decimal) (*big.Rat, error) {
+	switch n := node.(type) {
+	case *expression.Number:
+		return values[n.Index].Rat(), nil
+	case *expression.Unary:
+		operand, err := evaluateExact(input, n.Operand, values)
+		if err != nil {
+			return nil, err
+		}
+		return operand.Neg(operand), nil
+	case *expression.Binary:
+		left, err := evaluateExact(input, n.Left, values)
+		if err != nil {
+			return nil, err
+		}
+		right, err := evaluateExact(input, n.Right, values)
+		if err != nil {
+			return nil, err
+		}
+		if err := applyExact(binaryOperations[n.Op], left, right); err != nil {
+			return nil, fmt.Errorf("%w at column %d", err, expression.Column(input, n.Pos))
+		}
+		return left, nil
+	}
+	return nil, fmt.Errorf("unsupported expression node %T", node)
+}
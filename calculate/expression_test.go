@@ -0,0 +1,63 @@
+package calculate
+
+import (
+	"testing"
+
+	"challenge-calculator/expression"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{name: "precedence and parentheses", input: "(1.5 + 2) * 3 - 4 / 2", expected: "(1.5 + 2) * 3 - 4 / 2 = 8.5"},
+		{name: "empty input", input: "  ", expected: "0 = 0"},
+		{name: "negated group is allowed", input: "-(1 + 2) + 5", expected: "-(1 + 2) + 5 = 2"},
+		{name: "subtraction below zero is allowed", input: "1 - 4", expected: "1 - 4 = -3"},
+		{name: "negative literal rejected", input: "2 * -3 + -1", expectedErr: "invalid input: negative numbers found: -3, -1"},
+		{name: "negative literal allowed", opts: []Option{WithAllowNegatives(true)}, input: "2 * -3", expected: "2 * -3 = -6"},
+		{name: "number above max treated as zero", input: "2 + 1001 * 3", expected: "2 + 0 * 3 = 2"},
+		{name: "custom max", opts: []Option{WithMaxValidNumber(10)}, input: "5 * 11", expected: "5 * 0 = 0"},
+		{name: "division precision", opts: []Option{WithDivisionPrecision(3)}, input: "2 / 3", expected: "2 / 3 = 0.667"},
+		{name: "division by zero", input: "1 / (2 - 2)", expectedErr: "division by zero at column 3"},
+		{name: "division by dropped literal", input: "1 / 5000", expectedErr: "division by zero at column 3"},
+		{name: "syntax error", input: "(1 + 2", expectedErr: "syntax error at column 7: expected ')' to close '(' at column 1, found end of input"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := New(test.opts...).Evaluate(test.input)
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestEvaluateResult(t *testing.T) {
+	result, err := New().Evaluate("1 + 2000 * 3")
+	assert.NoError(t, err)
+
+	assert.Len(t, result.Terms, 3)
+	assert.Len(t, result.Kept, 2)
+	assert.Len(t, result.Dropped, 1)
+	assert.Equal(t, 1, result.Dropped[0].Index)
+	assert.Equal(t, DropReasonExceedsMax, result.Dropped[0].Reason)
+	assert.Equal(t, "1", result.Total.String())
+}
+
+func TestEvaluateSyntaxErrorType(t *testing.T) {
+	_, err := New().Evaluate("1 + ")
+	var syntaxErr *expression.SyntaxError
+	assert.ErrorAs(t, err, &syntaxErr)
+	assert.Equal(t, 5, syntaxErr.Column)
+}
@@ -0,0 +1,54 @@
+package calculate
+
+import (
+	"strings"
+)
+
+// Formatter renders a Result for display.
+type Formatter interface {
+	Format(result *Result) string
+}
+
+// FormulaFormatter renders results as "1+0+2 = 3", using the operation's
+// symbol and showing dropped terms as the value that replaced them. Ignored
+// negatives are shown as "[-3]" and absolute values as "|-3|". Money totals
+// end with their currency, as in "1.50+2 = 3.50 USD", and converted terms
+// are followed by the amount as written: "3.27 (3 EUR)". Terms written as
+// fractions are shown reduced, as in "1/3+1/2 = 5/6", and exact totals in
+// the format WithRational was given. With WithPrecision, numbers are shown
+// rounded, with every place if it is fixed: "1.10+2.00 = 3.10".
+type FormulaFormatter struct{}
+
+func (FormulaFormatter) Format(result *Result) string {
+	total := formatTotal(result.Total, result.Currency)
+	if result.precision != nil {
+		total = result.precision.format(result.Total)
+	}
+	if result.Fraction != nil {
+		total = formatExact(result.Fraction, result.rationalFormat, total)
+	}
+	if result.Expression != "" {
+		return result.Expression + " = " + total
+	}
+	if len(result.Terms) == 0 {
+		return "0 = " + total
+	}
+
+	formulaParts := make([]string, 0, len(result.Terms))
+	dropped, converted := 0, 0
+	for _, term := range result.Terms {
+		var droppedTerm *DroppedTerm
+		if dropped < len(result.Dropped) && result.Dropped[dropped].Index == term.Index {
+			droppedTerm = &result.Dropped[dropped]
+			dropped++
+		}
+		part := formatListTerm(result.Operation, term, droppedTerm, result.precision)
+		if converted < len(result.Conversions) && result.Conversions[converted].Index == term.Index {
+			part = formatConversion(part, &result.Conversions[converted])
+			converted++
+		}
+		formulaParts = append(formulaParts, part)
+	}
+
+	return strings.Join(formulaParts, result.Operation.Symbol()) + " = " + total
+}
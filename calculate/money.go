@@ -0,0 +1,235 @@
+package calculate
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"challenge-calculator/validate"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrMixedCurrencies is returned when terms in different currencies
+	// cannot be converted to one.
+	ErrMixedCurrencies = errors.New("mixed currencies")
+	// ErrInvalidMoney is returned for Money settings that cannot be used.
+	ErrInvalidMoney = errors.New("invalid money settings")
+)
+
+// Money makes a Calculator work with amounts of money. Terms may be written
+// with a currency symbol or ISO 4217 code, as validate.Currency accepts, and
+// the total is rounded to the minor units of its currency.
+type Money struct {
+	// Currency is the ISO 4217 code of the total, and of terms written
+	// without a currency. If empty, it is the currency of the first term
+	// written with one.
+	Currency string
+	// Rates converts terms in other currencies to the currency of the total.
+	// Without it, mixing currencies is an error.
+	Rates *Rates
+}
+
+// WithMoney calculates with amounts of money. Only addition and subtraction
+// are allowed on lists; expressions are rounded to Money.Currency.
+func WithMoney(money Money) Option {
+	return func(c *Calculator) {
+		c.money = &money
+		c.validatorOpts = append(c.validatorOpts, validate.WithCurrency())
+	}
+}
+
+// validate reports settings that cannot be used.
+func (m *Money) validate() error {
+	if m.Currency != "" {
+		if _, ok := validate.CurrencyMinorUnits(m.Currency); !ok {
+			return fmt.Errorf("%w: unknown currency code %q", ErrInvalidMoney, m.Currency)
+		}
+	}
+	return nil
+}
+
+// validateList reports settings that cannot be used to apply op to a list.
+func (m *Money) validateList(op Operation) error {
+	if op != OpAdd && op != OpSubtract {
+		return fmt.Errorf("%w: amounts of money can only be added or subtracted", ErrInvalidMoney)
+	}
+	return m.validate()
+}
+
+// Conversion is a term converted to the currency of the total. Amount and
+// Currency are the term as written, and Rate the units of the total's
+// currency that one unit of Currency is worth.
+type Conversion struct {
+	Index    int             `json:"index"`
+	Amount   decimal.Decimal `json:"amount"`
+	Currency string          `json:"currency"`
+	Rate     decimal.Decimal `json:"rate"`
+}
+
+// CurrencyError reports a term that is not in the currency of the total and
+// cannot be converted to it. Offset is the term's byte offset in the input.
+type CurrencyError struct {
+	Term     Term
+	Offset   int
+	Currency string
+	Expected string
+	// NoRate is set when there are rates, but none between the currencies.
+	NoRate bool
+}
+
+func (e *CurrencyError) Error() string {
+	message := fmt.Sprintf("%v: term %d (%s %s) is not in %s", ErrMixedCurrencies, e.Term.Index+1, e.Term.Value, e.Currency, e.Expected)
+	if e.NoRate {
+		message += fmt.Sprintf(" and there is no rate from %s to %s", e.Currency, e.Expected)
+	}
+	return message
+}
+
+func (e *CurrencyError) Is(target error) bool {
+	return target == ErrMixedCurrencies
+}
+
+// ledger tracks the currency of a calculation as its terms are read.
+type ledger struct {
+	money    *Money
+	currency string
+}
+
+func newLedger(money *Money) *ledger {
+	return &ledger{money: money, currency: money.Currency}
+}
+
+// convert returns the value of term, written in currency and found at
+// offset, in the currency of the total. The Conversion is nil unless the
+// term was in another currency.
+func (l *ledger) convert(term Term, currency string, offset int) (decimal.Decimal, *Conversion, error) {
+	if currency == "" || currency == l.currency {
+		return term.Value, nil, nil
+	}
+	if l.currency == "" {
+		l.currency = currency
+		return term.Value, nil, nil
+	}
+
+	if l.money.Rates == nil {
+		return decimal.Decimal{}, nil, &CurrencyError{Term: term, Offset: offset, Currency: currency, Expected: l.currency}
+	}
+	rate, ok := l.money.Rates.Rate(currency, l.currency)
+	if !ok {
+		return decimal.Decimal{}, nil, &CurrencyError{Term: term, Offset: offset, Currency: currency, Expected: l.currency, NoRate: true}
+	}
+	converted := l.round(term.Value.Mul(rate))
+	return converted, &Conversion{Index: term.Index, Amount: term.Value, Currency: currency, Rate: rate}, nil
+}
+
+// convertAll converts values to the currency of the total, recording the
+// conversions in result. values is only copied if a term is converted.
+func (l *ledger) convertAll(values []decimal.Decimal, currencies []string, offsets []int, result *Result) ([]decimal.Decimal, error) {
+	converted, copied := values, false
+	for i, currency := range currencies {
+		value, conversion, err := l.convert(Term{Index: i, Value: values[i]}, currency, offsets[i])
+		if err != nil {
+			return nil, err
+		}
+		if conversion == nil {
+			continue
+		}
+		if !copied {
+			converted, copied = append([]decimal.Decimal(nil), values...), true
+		}
+		converted[i] = value
+		result.Conversions = append(result.Conversions, *conversion)
+	}
+	return converted, nil
+}
+
+// round rounds value to the minor units of the total's currency, if it is
+// known.
+func (l *ledger) round(value decimal.Decimal) decimal.Decimal {
+	if digits, ok := validate.CurrencyMinorUnits(l.currency); ok {
+		return value.Round(digits)
+	}
+	return value
+}
+
+// formatTotal renders total, followed by its currency with every minor
+// digit if there is one.
+func formatTotal(total decimal.Decimal, currency string) string {
+	if digits, ok := validate.CurrencyMinorUnits(currency); ok {
+		return total.StringFixed(digits) + " " + currency
+	}
+	return total.String()
+}
+
+// formatConversion renders a converted term of a formula: its value in the
+// total's currency followed by the amount as written.
+func formatConversion(text string, conversion *Conversion) string {
+	return fmt.Sprintf("%s (%s %s)", text, conversion.Amount, conversion.Currency)
+}
+
+// Rates is a table of exchange rates.
+type Rates struct {
+	rates map[[2]string]decimal.Decimal
+}
+
+// Rate returns the units of to that one unit of from is worth. A rate the
+// other way round is inverted when there is no direct one.
+func (r *Rates) Rate(from, to string) (decimal.Decimal, bool) {
+	if rate, ok := r.rates[[2]string{from, to}]; ok {
+		return rate, true
+	}
+	if rate, ok := r.rates[[2]string{to, from}]; ok {
+		return decimal.NewFromInt(1).DivRound(rate, defaultDivisionPrecision), true
+	}
+	return decimal.Decimal{}, false
+}
+
+// ReadRates reads a rate table with one rate per line, written as
+// "EUR,USD,1.09" for 1 EUR = 1.09 USD. Blank lines and lines starting with
+// "#" are skipped.
+func ReadRates(r io.Reader) (*Rates, error) {
+	rates := &Rates{rates: make(map[[2]string]decimal.Decimal)}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, ",")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("rates line %d: want from,to,rate, got %q", line, text)
+		}
+		from := strings.ToUpper(strings.TrimSpace(fields[0]))
+		to := strings.ToUpper(strings.TrimSpace(fields[1]))
+		for _, code := range []string{from, to} {
+			if _, ok := validate.CurrencyMinorUnits(code); !ok {
+				return nil, fmt.Errorf("rates line %d: unknown currency code %q", line, code)
+			}
+		}
+		rate, err := decimal.NewFromString(strings.TrimSpace(fields[2]))
+		if err != nil || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("rates line %d: invalid rate %q", line, strings.TrimSpace(fields[2]))
+		}
+		rates.rates[[2]string{from, to}] = rate
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// LoadRates reads a rate table from a file. See ReadRates.
+func LoadRates(path string) (*Rates, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadRates(file)
+}
@@ -0,0 +1,124 @@
+package calculate
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func testRates(t *testing.T) *Rates {
+	rates, err := ReadRates(strings.NewReader("# from,to,rate\nEUR,USD,1.09\n\nusd, jpy, 150\n"))
+	assert.NoError(t, err)
+	return rates
+}
+
+func TestReadRates(t *testing.T) {
+	rates := testRates(t)
+
+	rate, ok := rates.Rate("EUR", "USD")
+	assert.True(t, ok)
+	assert.Equal(t, "1.09", rate.String())
+	rate, ok = rates.Rate("JPY", "USD")
+	assert.True(t, ok)
+	assert.Equal(t, "0.0066666666666667", rate.String())
+	_, ok = rates.Rate("EUR", "GBP")
+	assert.False(t, ok)
+
+	for input, expectedErr := range map[string]string{
+		"EUR,USD":        `rates line 1: want from,to,rate, got "EUR,USD"`,
+		"\nEUR,XXX,1":    `rates line 2: unknown currency code "XXX"`,
+		"EUR,USD,lots":   `rates line 1: invalid rate "lots"`,
+		"EUR,USD,-1.09":  `rates line 1: invalid rate "-1.09"`,
+		"EUR,USD,1,2,3":  `rates line 1: want from,to,rate, got "EUR,USD,1,2,3"`,
+		"# only\nGBP,,1": `rates line 2: unknown currency code ""`,
+	} {
+		_, err := ReadRates(strings.NewReader(input))
+		assert.EqualError(t, err, expectedErr, input)
+	}
+}
+
+func TestMoney(t *testing.T) {
+	tests := []struct {
+		name        string
+		money       Money
+		op          Operation
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{name: "one currency", op: OpAdd, input: "12.50 USD,3 USD", expected: "12.5+3 = 15.50 USD"},
+		{name: "symbols and plain numbers", op: OpAdd, input: "$12.50,3", expected: "12.5+3 = 15.50 USD"},
+		{name: "no currency", op: OpAdd, input: "1.005,2", expected: "1.005+2 = 3.005"},
+		{name: "default currency", money: Money{Currency: "EUR"}, op: OpAdd, input: "1.005,2", expected: "1.005+2 = 3.01 EUR"},
+		{name: "zero minor units", op: OpAdd, input: "¥100.4,¥200", expected: "100.4+200 = 300 JPY"},
+		{name: "three minor units", op: OpSubtract, input: "10 KWD,0.0004 KWD", expected: "10-0.0004 = 10.000 KWD"},
+		{name: "mixed currencies", op: OpAdd, input: "12.50 USD,3 EUR", expectedErr: "mixed currencies: term 2 (3 EUR) is not in USD"},
+		{name: "mixed with default currency", money: Money{Currency: "EUR"}, op: OpAdd, input: "3,$1", expectedErr: "mixed currencies: term 2 (1 USD) is not in EUR"},
+		{name: "converted", money: Money{Rates: &Rates{}}, op: OpAdd, input: "1 USD,3 EUR", expectedErr: "mixed currencies: term 2 (3 EUR) is not in USD and there is no rate from EUR to USD"},
+		{name: "multiply", op: OpMultiply, input: "1 USD", expectedErr: "invalid money settings: amounts of money can only be added or subtracted"},
+		{name: "unknown currency", money: Money{Currency: "XXX"}, op: OpAdd, input: "1", expectedErr: `invalid money settings: unknown currency code "XXX"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calculator := New(WithMoney(test.money))
+			result, err := calculator.Calculate(test.op, test.input)
+
+			var formula bytes.Buffer
+			_, streamErr := calculator.CalculateStream(test.op, strings.NewReader(test.input), &formula)
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				assert.EqualError(t, streamErr, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, result.String())
+			assert.NoError(t, streamErr)
+			assert.Equal(t, test.expected, formula.String())
+		})
+	}
+}
+
+func TestMoneyConversion(t *testing.T) {
+	calculator := New(WithMoney(Money{Currency: "USD", Rates: testRates(t)}))
+	result, err := calculator.Add("12.50,3 EUR,¥1000")
+	assert.NoError(t, err)
+	assert.Equal(t, "12.5+3.27 (3 EUR)+6.67 (1000 JPY) = 22.44 USD", result.String())
+	assert.Equal(t, "USD", result.Currency)
+	assert.Equal(t, []Conversion{
+		{Index: 1, Amount: decimal.NewFromInt(3), Currency: "EUR", Rate: decimal.RequireFromString("1.09")},
+		{Index: 2, Amount: decimal.NewFromInt(1000), Currency: "JPY", Rate: decimal.RequireFromString("0.0066666666666667")},
+	}, result.Conversions)
+	assert.Equal(t, "3.27", result.Terms[1].Value.String())
+
+	var formula bytes.Buffer
+	streamed, err := calculator.CalculateStream(OpAdd, strings.NewReader("12.50,3 EUR,¥1000"), &formula)
+	assert.NoError(t, err)
+	assert.Equal(t, result.String(), formula.String())
+	assert.Equal(t, "USD", streamed.Currency)
+	assert.Equal(t, 2, streamed.ConvertedCount)
+
+	// The range applies to the converted value.
+	result, err = New(WithMoney(Money{Currency: "USD", Rates: testRates(t)}), WithMax(bound("1000", false, BoundExclude))).Add("1 USD,950 EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, "1+0 (950 EUR) = 1.00 USD", result.String())
+}
+
+func TestMoneyErrors(t *testing.T) {
+	_, err := New(WithMoney(Money{})).Add("1 USD, 3 EUR")
+	var currencyErr *CurrencyError
+	assert.True(t, errors.As(err, &currencyErr))
+	assert.True(t, errors.Is(err, ErrMixedCurrencies))
+	assert.Equal(t, 7, currencyErr.Offset)
+	assert.Equal(t, "EUR", currencyErr.Currency)
+	assert.Equal(t, "USD", currencyErr.Expected)
+
+	result, err := New(WithMoney(Money{Currency: "USD"})).Evaluate("3 * 1.255")
+	assert.NoError(t, err)
+	assert.Equal(t, "3 * 1.255 = 3.77 USD", result.String())
+}
@@ -0,0 +1,60 @@
+package calculate
+
+import (
+	"errors"
+	"fmt"
+
+	"challenge-calculator/validate"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrNegativeTotal is returned under validate.NegativesErrorTotal when the
+// result is negative.
+var ErrNegativeTotal = errors.New("negative total")
+
+// applyTerm works out the value to calculate with for term, found at offset
+// in the input, by applying the negatives policy and then the range. See
+// applyRange for what it returns.
+func (c *Calculator) applyTerm(term Term, offset int, identity decimal.Decimal) (decimal.Decimal, *DroppedTerm, string, error) {
+	if term.Value.Sign() >= 0 {
+		return c.applyRange(term, term.Value, offset, identity)
+	}
+
+	switch c.validator.Negatives() {
+	case validate.NegativesIgnore:
+		return identity, &DroppedTerm{Term: term, Reason: DropReasonNegative, Detail: "negative number ignored", Replacement: identity}, "", nil
+	case validate.NegativesAbs:
+		value, dropped, warning, err := c.applyRange(term, term.Value.Abs(), offset, identity)
+		if err == nil && dropped == nil {
+			dropped = &DroppedTerm{Term: term, Reason: DropReasonAbsolute, Detail: "negative number replaced with its absolute value", Replacement: value}
+		}
+		return value, dropped, warning, err
+	}
+	return c.applyRange(term, term.Value, offset, identity)
+}
+
+// checkTotal rejects a negative total under validate.NegativesErrorTotal.
+func (c *Calculator) checkTotal(total decimal.Decimal) error {
+	if c.validator.Negatives() == validate.NegativesErrorTotal && total.Sign() < 0 {
+		return fmt.Errorf("%w: %s", ErrNegativeTotal, total)
+	}
+	return nil
+}
+
+// formatTerm renders a term of a formula. Dropped terms show the value used
+// in their place, except that ignored negatives are shown in brackets and
+// negatives replaced with their absolute value between bars. Numbers are
+// rounded to p.
+func formatTerm(term Term, dropped *DroppedTerm, p *Precision) string {
+	if dropped == nil {
+		return term.text(p)
+	}
+	switch dropped.Reason {
+	case DropReasonNegative:
+		return "[" + term.text(p) + "]"
+	case DropReasonAbsolute:
+		return "|" + term.text(p) + "|"
+	}
+	return p.format(dropped.Replacement)
+}
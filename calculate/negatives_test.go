@@ -0,0 +1,101 @@
+package calculate
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"challenge-calculator/validate"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegativesPolicies(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		op          Operation
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{name: "error", opts: []Option{WithNegatives(validate.NegativesError)}, op: OpAdd, input: "1,-3,2", expectedErr: "invalid input: negative numbers found: -3"},
+		{name: "allow", opts: []Option{WithNegatives(validate.NegativesAllow)}, op: OpAdd, input: "1,-3,2", expected: "1+-3+2 = 0"},
+		{name: "ignore", opts: []Option{WithNegatives(validate.NegativesIgnore)}, op: OpAdd, input: "1,-3,2", expected: "1+[-3]+2 = 3"},
+		{name: "ignore when multiplying", opts: []Option{WithNegatives(validate.NegativesIgnore)}, op: OpMultiply, input: "-2,3,-1.5", expected: "[-2]*3*[-1.5] = 3"},
+		{name: "abs", opts: []Option{WithNegatives(validate.NegativesAbs)}, op: OpSubtract, input: "10,-3,2", expected: "10-|-3|-2 = 5"},
+		{name: "abs then above max", opts: []Option{WithNegatives(validate.NegativesAbs)}, op: OpAdd, input: "1,-2000", expected: "1+0 = 1"},
+		{name: "abs then clamped", opts: []Option{WithNegatives(validate.NegativesAbs), WithMax(bound("10", false, BoundClamp))}, op: OpAdd, input: "1,-20", expected: "1+10 = 11"},
+		{name: "ignore before min", opts: []Option{WithNegatives(validate.NegativesIgnore), WithMin(bound("0", false, BoundError))}, op: OpAdd, input: "1,-2", expected: "1+[-2] = 1"},
+		{name: "allow then below min", opts: []Option{WithNegatives(validate.NegativesAllow), WithMin(bound("-1", false, BoundClamp))}, op: OpAdd, input: "1,-2", expected: "1+-1 = 0"},
+		{name: "error total with positive total", opts: []Option{WithNegatives(validate.NegativesErrorTotal)}, op: OpAdd, input: "5,-3", expected: "5+-3 = 2"},
+		{name: "error total with negative total", opts: []Option{WithNegatives(validate.NegativesErrorTotal)}, op: OpAdd, input: "1,-3", expectedErr: "negative total: -2"},
+		{name: "error total with negative product", opts: []Option{WithNegatives(validate.NegativesErrorTotal)}, op: OpMultiply, input: "2,-3", expectedErr: "negative total: -6"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calculator := New(test.opts...)
+			result, err := calculator.Calculate(test.op, test.input)
+
+			var formula bytes.Buffer
+			_, streamErr := calculator.CalculateStream(test.op, strings.NewReader(test.input), &formula)
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				assert.EqualError(t, streamErr, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, result.String())
+			assert.NoError(t, streamErr)
+			assert.Equal(t, test.expected, formula.String())
+		})
+	}
+}
+
+func TestNegativesResult(t *testing.T) {
+	result, err := New(WithNegatives(validate.NegativesAbs)).Add("1,-2.5")
+	assert.NoError(t, err)
+	assert.Equal(t, []DroppedTerm{{
+		Term:        Term{Index: 1, Value: decimal.RequireFromString("-2.5")},
+		Reason:      DropReasonAbsolute,
+		Detail:      "negative number replaced with its absolute value",
+		Replacement: decimal.RequireFromString("2.5"),
+	}}, result.Dropped)
+
+	result, err = New(WithNegatives(validate.NegativesIgnore)).Add("1,-2")
+	assert.NoError(t, err)
+	assert.Equal(t, []DroppedTerm{{
+		Term:        Term{Index: 1, Value: decimal.NewFromInt(-2)},
+		Reason:      DropReasonNegative,
+		Detail:      "negative number ignored",
+		Replacement: decimal.Zero,
+	}}, result.Dropped)
+	assert.Equal(t, []Term{{Index: 0, Value: decimal.NewFromInt(1)}}, result.Kept)
+
+	result, err = New(WithNegatives(validate.NegativesAbs)).Evaluate("-3 + 4 * -2")
+	assert.NoError(t, err)
+	assert.Equal(t, "|-3| + 4 * |-2| = 11", result.String())
+
+	result, err = New(WithNegatives(validate.NegativesIgnore)).Evaluate("-3 + 4 * -2")
+	assert.NoError(t, err)
+	assert.Equal(t, "[-3] + 4 * [-2] = 0", result.String())
+
+	_, err = New(WithNegatives(validate.NegativesErrorTotal)).Evaluate("1 - 3")
+	assert.True(t, errors.Is(err, ErrNegativeTotal))
+}
+
+func TestNegativesWithWorkers(t *testing.T) {
+	input := largeInput(3*minParallelTerms, "1", "-2", "3.5", "-0.25")
+	for _, policy := range []validate.NegativesPolicy{validate.NegativesAllow, validate.NegativesIgnore, validate.NegativesAbs} {
+		expected, err := New(WithNegatives(policy)).Add(input)
+		assert.NoError(t, err)
+		actual, err := New(WithNegatives(policy), WithWorkers(4)).Add(input)
+		assert.NoError(t, err)
+		assert.Equal(t, expected.String(), actual.String(), policy)
+		assert.Equal(t, expected.Dropped, actual.Dropped, policy)
+	}
+}
@@ -0,0 +1,97 @@
+package calculate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+type Operation string
+
+const (
+	OpAdd      Operation = "add"
+	OpSubtract Operation = "subtract"
+	OpMultiply Operation = "multiply"
+	OpDivide   Operation = "divide"
+)
+
+var ErrDivideByZero = errors.New("division by zero")
+
+var operationAliases = map[string]Operation{
+	"add":      OpAdd,
+	"+":        OpAdd,
+	"sub":      OpSubtract,
+	"subtract": OpSubtract,
+	"-":        OpSubtract,
+	"mul":      OpMultiply,
+	"multiply": OpMultiply,
+	"*":        OpMultiply,
+	"div":      OpDivide,
+	"divide":   OpDivide,
+	"/":        OpDivide,
+}
+
+// ParseOperation accepts an operation name, its short form or its symbol.
+func ParseOperation(name string) (Operation, error) {
+	op, ok := operationAliases[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return "", fmt.Errorf("unknown operation: %q", name)
+	}
+	return op, nil
+}
+
+// SplitOperationPrefix picks the operation for a single line of input written
+// as "mul:2,3". Input without a recognised prefix is returned unchanged.
+func SplitOperationPrefix(input string) (Operation, string, bool) {
+	name, rest, found := strings.Cut(input, ":")
+	if !found {
+		return "", input, false
+	}
+	op, err := ParseOperation(name)
+	if err != nil {
+		return "", input, false
+	}
+	return op, rest, true
+}
+
+// Symbol is the operator shown between terms in a formula.
+func (op Operation) Symbol() string {
+	switch op {
+	case OpSubtract:
+		return "-"
+	case OpMultiply:
+		return "*"
+	case OpDivide:
+		return "/"
+	default:
+		return "+"
+	}
+}
+
+// identity is substituted for dropped terms so they do not change the result.
+func (op Operation) identity() decimal.Decimal {
+	switch op {
+	case OpMultiply, OpDivide:
+		return decimal.NewFromInt(1)
+	default:
+		return decimal.Zero
+	}
+}
+
+func (op Operation) apply(total, value decimal.Decimal, divisionPrecision int32) (decimal.Decimal, error) {
+	switch op {
+	case OpSubtract:
+		return total.Sub(value), nil
+	case OpMultiply:
+		return total.Mul(value), nil
+	case OpDivide:
+		if value.IsZero() {
+			return decimal.Zero, ErrDivideByZero
+		}
+		return total.DivRound(value, divisionPrecision), nil
+	default:
+		return total.Add(value), nil
+	}
+}
@@ -0,0 +1,59 @@
+package calculate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOperation(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    Operation
+		expectedErr string
+	}{
+		{name: "full name", input: "multiply", expected: OpMultiply},
+		{name: "short name", input: "sub", expected: OpSubtract},
+		{name: "symbol", input: "/", expected: OpDivide},
+		{name: "mixed case with whitespace", input: " Add ", expected: OpAdd},
+		{name: "unknown operation", input: "pow", expectedErr: "unknown operation: \"pow\""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := ParseOperation(test.input)
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, result)
+			}
+		})
+	}
+}
+
+func TestSplitOperationPrefix(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expectedOp    Operation
+		expectedInput string
+		expectedFound bool
+	}{
+		{name: "no prefix", input: "1,2", expectedInput: "1,2"},
+		{name: "named prefix", input: "mul:2,3", expectedOp: OpMultiply, expectedInput: "2,3", expectedFound: true},
+		{name: "symbol prefix", input: "/:8,2", expectedOp: OpDivide, expectedInput: "8,2", expectedFound: true},
+		{name: "prefix before custom delimiter", input: "sub://;\n5;2", expectedOp: OpSubtract, expectedInput: "//;\n5;2", expectedFound: true},
+		{name: "unknown prefix left alone", input: "abc:1,2", expectedInput: "abc:1,2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			op, input, found := SplitOperationPrefix(test.input)
+			assert.Equal(t, test.expectedOp, op)
+			assert.Equal(t, test.expectedInput, input)
+			assert.Equal(t, test.expectedFound, found)
+		})
+	}
+}
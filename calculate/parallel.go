@@ -0,0 +1,61 @@
+package calculate
+
+import (
+	"math/big"
+	"sync"
+
+	"challenge-calculator/internal/chunk"
+
+	"github.com/shopspring/decimal"
+)
+
+// minParallelTerms is the smallest number of terms worth handing to a worker
+// of its own.
+const minParallelTerms = 4096
+
+// applyParallel folds values in one contiguous chunk per worker and then
+// combines the partial totals in order. Addition and multiplication of
+// decimals are exact, so the total is the same as folding in one pass. For
+// subtraction every term after the first is subtracted, so the later chunks
+// are summed and their sums subtracted.
+func (c *Calculator) applyParallel(op Operation, values []decimal.Decimal, fractions []*big.Rat, offsets []int, result *Result) error {
+	ranges := chunk.Ranges(len(values), c.workers, minParallelTerms)
+	chunks := make([]foldedChunk, len(ranges))
+	errs := make([]error, len(ranges))
+
+	var wg sync.WaitGroup
+	for i, r := range ranges {
+		chunkOp := op
+		if op == OpSubtract && i > 0 {
+			chunkOp = OpAdd
+		}
+		wg.Add(1)
+		go func(i, start, end int, chunkOp Operation) {
+			defer wg.Done()
+			var chunkFractions []*big.Rat
+			if fractions != nil {
+				chunkFractions = fractions[start:end]
+			}
+			chunks[i], errs[i] = c.fold(chunkOp, values[start:end], chunkFractions, offsets[start:end], start, result.Terms[start:end])
+		}(i, r[0], r[1], chunkOp)
+	}
+	wg.Wait()
+
+	for i, chunk := range chunks {
+		if errs[i] != nil {
+			return errs[i]
+		}
+		result.Kept = append(result.Kept, chunk.kept...)
+		result.Dropped = append(result.Dropped, chunk.dropped...)
+		result.Warnings = append(result.Warnings, chunk.warnings...)
+		if i == 0 {
+			result.Total = chunk.total
+			continue
+		}
+		var err error
+		if result.Total, err = op.apply(result.Total, chunk.total, c.divisionPrecision); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package calculate
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// largeInput repeats parts, separated by commas, until it has terms terms.
+func largeInput(terms int, parts ...string) string {
+	var sb strings.Builder
+	for i := 0; i < terms; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(parts[i%len(parts)])
+	}
+	return sb.String()
+}
+
+func TestWorkersMatchSequential(t *testing.T) {
+	tests := []struct {
+		name  string
+		op    Operation
+		input string
+	}{
+		{name: "small add", op: OpAdd, input: "1,2,3"},
+		{name: "add", op: OpAdd, input: largeInput(5*minParallelTerms+7, "1", "2.5", "abc", "1001", "0.125", "", "7")},
+		{name: "subtract", op: OpSubtract, input: largeInput(5*minParallelTerms+7, "1", "2.5", "abc", "1001", "0.125", "", "7")},
+		{name: "subtract dropped first", op: OpSubtract, input: largeInput(3*minParallelTerms, "1001", "2", "3.75")},
+		{name: "multiply", op: OpMultiply, input: largeInput(3*minParallelTerms, "1", "1.5", "1001", "0.5", "1")},
+		{name: "divide", op: OpDivide, input: largeInput(3*minParallelTerms, "1", "1.5", "0.5", "2")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expected, err := New().Calculate(test.op, test.input)
+			assert.NoError(t, err)
+
+			for _, workers := range []int{2, 4, 7} {
+				actual, err := New(WithWorkers(workers)).Calculate(test.op, test.input)
+				assert.NoError(t, err)
+				assert.Equal(t, expected.String(), actual.String(), "%d workers", workers)
+				assert.Equal(t, expected.Total.String(), actual.Total.String(), "%d workers", workers)
+				assert.Equal(t, expected.Terms, actual.Terms, "%d workers", workers)
+				assert.Equal(t, expected.Kept, actual.Kept, "%d workers", workers)
+				assert.Equal(t, expected.Dropped, actual.Dropped, "%d workers", workers)
+				assert.Equal(t, expected.Warnings, actual.Warnings, "%d workers", workers)
+
+				var formula bytes.Buffer
+				streamed, err := New(WithWorkers(workers)).CalculateStream(test.op, strings.NewReader(test.input), &formula)
+				assert.NoError(t, err)
+				assert.Equal(t, expected.String(), formula.String(), "%d workers, streamed", workers)
+				assert.Equal(t, expected.Total.String(), streamed.Total.String(), "%d workers, streamed", workers)
+			}
+		})
+	}
+}
+
+func TestWorkersErrors(t *testing.T) {
+	input := largeInput(3*minParallelTerms, "1", "2") + ",0"
+	_, expectedErr := New().Calculate(OpDivide, input)
+	_, err := New(WithWorkers(4)).Calculate(OpDivide, input)
+	assert.ErrorIs(t, err, ErrDivideByZero)
+	assert.Equal(t, expectedErr, err)
+}
+
+func BenchmarkCalculateWorkers(b *testing.B) {
+	for _, size := range []int{1 << 20, 8 << 20} {
+		// "123.45," is seven bytes.
+		input := largeInput(size/7, "123.45", "7", "0.5", "999")
+		for _, workers := range []int{1, 2, 4, 8} {
+			b.Run(fmt.Sprintf("%dMB/%d workers", size>>20, workers), func(b *testing.B) {
+				calculator := New(WithWorkers(workers))
+				b.SetBytes(int64(len(input)))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := calculator.Add(input); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkCalculateStreamWorkers(b *testing.B) {
+	input := largeInput((8<<20)/7, "123.45", "7", "0.5", "999")
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("8MB/%d workers", workers), func(b *testing.B) {
+			calculator := New(WithWorkers(workers))
+			b.SetBytes(int64(len(input)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := calculator.CalculateStream(OpAdd, strings.NewReader(input), io.Discard); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
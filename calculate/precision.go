@@ -0,0 +1,174 @@
+package calculate
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// RoundingMode is how numbers are rounded to a Precision.
+type RoundingMode string
+
+const (
+	// RoundHalfUp rounds halves away from zero: 2.5 is 3 and -2.5 is -3.
+	RoundHalfUp RoundingMode = "half-up"
+	// RoundHalfEven rounds halves to the even neighbour: 2.5 is 2 and 3.5
+	// is 4.
+	RoundHalfEven RoundingMode = "half-even"
+	// RoundDown rounds towards zero.
+	RoundDown RoundingMode = "down"
+	// RoundCeil rounds towards positive infinity.
+	RoundCeil RoundingMode = "ceil"
+	// RoundFloor rounds towards negative infinity.
+	RoundFloor RoundingMode = "floor"
+)
+
+// MaxPrecisionPlaces is the most decimal places a Precision can have.
+// Rounding to millions of places takes minutes and megabytes per number.
+const MaxPrecisionPlaces = 100
+
+// ErrInvalidPrecision is returned for Precision settings that cannot be used.
+var ErrInvalidPrecision = errors.New("invalid precision settings")
+
+// ParseRoundingMode accepts the name of a RoundingMode.
+func ParseRoundingMode(name string) (RoundingMode, error) {
+	mode := RoundingMode(strings.ToLower(strings.TrimSpace(name)))
+	switch mode {
+	case RoundHalfUp, RoundHalfEven, RoundDown, RoundCeil, RoundFloor:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown rounding mode: %q", name)
+}
+
+// Precision rounds the terms and total of a calculation to Places decimal
+// places, both as shown and as returned. An empty Rounding means
+// RoundHalfUp. Fixed shows every place, so 3.1 is shown as 3.10 with two
+// places. RoundTerms rounds each term before it is used, so the total is
+// calculated from the rounded terms; otherwise terms are only rounded for
+// display and the total is rounded once at the end.
+type Precision struct {
+	Places     int32
+	Rounding   RoundingMode
+	Fixed      bool
+	RoundTerms bool
+}
+
+// WithPrecision rounds terms and totals as p says. It cannot be combined
+// with WithMoney, which rounds to the currency.
+func WithPrecision(p Precision) Option {
+	return func(c *Calculator) {
+		c.precision = &p
+	}
+}
+
+func (p *Precision) rounding() RoundingMode {
+	if p.Rounding == "" {
+		return RoundHalfUp
+	}
+	return p.Rounding
+}
+
+// validatePrecision reports precision settings that cannot be used.
+func (c *Calculator) validatePrecision() error {
+	p := c.precision
+	if p == nil {
+		return nil
+	}
+	if p.Places < 0 || p.Places > MaxPrecisionPlaces {
+		return fmt.Errorf("%w: %d decimal places, must be from 0 to %d", ErrInvalidPrecision, p.Places, MaxPrecisionPlaces)
+	}
+	if _, err := ParseRoundingMode(string(p.rounding())); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPrecision, err)
+	}
+	if c.money != nil {
+		return fmt.Errorf("%w: amounts of money are rounded to their currency", ErrInvalidPrecision)
+	}
+	return nil
+}
+
+// round rounds value to the precision. A nil Precision leaves it as it is.
+func (p *Precision) round(value decimal.Decimal) decimal.Decimal {
+	if p == nil {
+		return value
+	}
+	switch p.rounding() {
+	case RoundHalfEven:
+		return value.RoundBank(p.Places)
+	case RoundDown:
+		return value.RoundDown(p.Places)
+	case RoundCeil:
+		return value.RoundCeil(p.Places)
+	case RoundFloor:
+		return value.RoundFloor(p.Places)
+	}
+	return value.Round(p.Places)
+}
+
+// roundExact rounds an exact value to the precision, without rounding it
+// to a decimal first.
+func (p *Precision) roundExact(value *big.Rat) decimal.Decimal {
+	scaled := new(big.Int).Mul(value.Num(), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(p.Places)), nil))
+	quotient, remainder := new(big.Int).QuoRem(scaled, value.Denom(), new(big.Int))
+
+	// QuoRem truncates, so quotient is rounded down; step away from zero
+	// when the mode says so.
+	away := false
+	if remainder.Sign() != 0 {
+		switch p.rounding() {
+		case RoundCeil:
+			away = value.Sign() > 0
+		case RoundFloor:
+			away = value.Sign() < 0
+		case RoundHalfUp, RoundHalfEven:
+			cmp := new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(value.Denom())
+			away = cmp > 0 || (cmp == 0 && (p.rounding() == RoundHalfUp || quotient.Bit(0) == 1))
+		}
+	}
+	if away {
+		quotient.Add(quotient, big.NewInt(int64(value.Sign())))
+	}
+	return decimal.NewFromBigInt(quotient, -p.Places)
+}
+
+// roundTerm rounds a term before it is used, if the precision says so. A
+// rounded fraction becomes a decimal.
+func (c *Calculator) roundTerm(term Term) Term {
+	if c.precision == nil || !c.precision.RoundTerms {
+		return term
+	}
+	if term.Fraction != nil {
+		term.Value = c.precision.roundExact(term.Fraction)
+		term.Fraction = nil
+		return term
+	}
+	term.Value = c.precision.round(term.Value)
+	return term
+}
+
+// roundTotal rounds the total of a calculation to the precision, or an
+// exact total to the division precision when there is none.
+func (c *Calculator) roundTotal(total decimal.Decimal, exact *big.Rat) decimal.Decimal {
+	switch {
+	case c.precision != nil && exact != nil:
+		return c.precision.roundExact(exact)
+	case exact != nil:
+		return decimal.NewFromBigRat(exact, c.divisionPrecision)
+	}
+	return c.precision.round(total)
+}
+
+// format renders value rounded to the precision, with every place if it is
+// fixed. A nil Precision renders value as it is.
+func (p *Precision) format(value decimal.Decimal) string {
+	if p == nil {
+		return value.String()
+	}
+	value = p.round(value)
+	if p.Fixed {
+		return value.StringFixed(p.Places)
+	}
+	return value.String()
+}
@@ -0,0 +1,145 @@
+package calculate
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRoundingMode(t *testing.T) {
+	mode, err := ParseRoundingMode(" Half-Even ")
+	assert.NoError(t, err)
+	assert.Equal(t, RoundHalfEven, mode)
+
+	_, err = ParseRoundingMode("sideways")
+	assert.EqualError(t, err, `unknown rounding mode: "sideways"`)
+}
+
+func TestPrecisionRound(t *testing.T) {
+	tests := []struct {
+		mode     RoundingMode
+		places   int32
+		value    string
+		expected string
+	}{
+		{mode: "", places: 0, value: "2.5", expected: "3"},
+		{mode: RoundHalfUp, places: 0, value: "2.5", expected: "3"},
+		{mode: RoundHalfUp, places: 0, value: "-2.5", expected: "-3"},
+		{mode: RoundHalfEven, places: 0, value: "2.5", expected: "2"},
+		{mode: RoundHalfEven, places: 0, value: "3.5", expected: "4"},
+		{mode: RoundHalfEven, places: 0, value: "-2.5", expected: "-2"},
+		{mode: RoundDown, places: 1, value: "1.99", expected: "1.9"},
+		{mode: RoundDown, places: 1, value: "-1.99", expected: "-1.9"},
+		{mode: RoundCeil, places: 1, value: "1.01", expected: "1.1"},
+		{mode: RoundCeil, places: 1, value: "-1.09", expected: "-1"},
+		{mode: RoundFloor, places: 1, value: "1.09", expected: "1"},
+		{mode: RoundFloor, places: 1, value: "-1.01", expected: "-1.1"},
+		{mode: RoundHalfUp, places: 2, value: "1.005", expected: "1.01"},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.mode)+" "+test.value, func(t *testing.T) {
+			p := &Precision{Places: test.places, Rounding: test.mode}
+			value := decimal.RequireFromString(test.value)
+			assert.Equal(t, test.expected, p.round(value).String())
+			assert.Equal(t, test.expected, p.roundExact(value.Rat()).String())
+		})
+	}
+}
+
+func TestPrecisionRoundExact(t *testing.T) {
+	tests := []struct {
+		mode     RoundingMode
+		value    *big.Rat
+		expected string
+	}{
+		{mode: RoundHalfUp, value: big.NewRat(2, 3), expected: "0.67"},
+		{mode: RoundHalfUp, value: big.NewRat(-2, 3), expected: "-0.67"},
+		{mode: RoundHalfEven, value: big.NewRat(1, 8), expected: "0.12"},
+		{mode: RoundDown, value: big.NewRat(2, 3), expected: "0.66"},
+		{mode: RoundCeil, value: big.NewRat(1, 3), expected: "0.34"},
+		{mode: RoundFloor, value: big.NewRat(-1, 3), expected: "-0.34"},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.mode)+" "+test.value.String(), func(t *testing.T) {
+			p := &Precision{Places: 2, Rounding: test.mode}
+			assert.Equal(t, test.expected, p.roundExact(test.value).String())
+		})
+	}
+}
+
+func TestPrecision(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		op          Operation
+		input       string
+		expected    string
+		total       string
+		expectedErr string
+	}{
+		{name: "display only", opts: []Option{WithPrecision(Precision{Places: 1})}, op: OpAdd, input: "1.14,1.14", expected: "1.1+1.1 = 2.3", total: "2.3"},
+		{name: "round terms", opts: []Option{WithPrecision(Precision{Places: 1, RoundTerms: true})}, op: OpAdd, input: "1.14,1.14", expected: "1.1+1.1 = 2.2", total: "2.2"},
+		{name: "fixed", opts: []Option{WithPrecision(Precision{Places: 2, Fixed: true})}, op: OpAdd, input: "1.1,2", expected: "1.10+2.00 = 3.10", total: "3.1"},
+		{name: "half even", opts: []Option{WithPrecision(Precision{Rounding: RoundHalfEven})}, op: OpAdd, input: "1.25,1.25", expected: "1+1 = 2", total: "2"},
+		{name: "division", opts: []Option{WithPrecision(Precision{Places: 3, Rounding: RoundDown})}, op: OpDivide, input: "2,3", expected: "2/3 = 0.666", total: "0.666"},
+		{name: "negative", opts: []Option{WithPrecision(Precision{Places: 0, Rounding: RoundCeil}), WithAllowNegatives(true)}, op: OpAdd, input: "-1.5,0.2", expected: "-1+1 = -1", total: "-1"},
+		{name: "dropped term", opts: []Option{WithPrecision(Precision{Places: 1, Fixed: true})}, op: OpAdd, input: "1.26,1001", expected: "1.3+0.0 = 1.3", total: "1.3"},
+		{name: "rational", opts: []Option{WithRational(RationalFraction), WithPrecision(Precision{Places: 2, Rounding: RoundCeil})}, op: OpAdd, input: "1/3,1/3", expected: "1/3+1/3 = 2/3", total: "0.67"},
+		{name: "rational decimal", opts: []Option{WithRational(RationalDecimal), WithPrecision(Precision{Places: 3, Fixed: true})}, op: OpAdd, input: "1/4,1/4", expected: "1/4+1/4 = 0.500", total: "0.5"},
+		{name: "rational round terms", opts: []Option{WithRational(RationalMixed), WithPrecision(Precision{Places: 1, RoundTerms: true})}, op: OpAdd, input: "1/3,1 1/3", expected: "0.3+1.3 = 1 3/5", total: "1.6"},
+		{name: "negative places", opts: []Option{WithPrecision(Precision{Places: -1})}, op: OpAdd, input: "1", expectedErr: "invalid precision settings: -1 decimal places, must be from 0 to 100"},
+		{name: "too many places", opts: []Option{WithPrecision(Precision{Places: MaxPrecisionPlaces + 1, Fixed: true})}, op: OpAdd, input: "1", expectedErr: "invalid precision settings: 101 decimal places, must be from 0 to 100"},
+		{name: "unknown mode", opts: []Option{WithPrecision(Precision{Rounding: "sideways"})}, op: OpAdd, input: "1", expectedErr: `invalid precision settings: unknown rounding mode: "sideways"`},
+		{name: "money", opts: []Option{WithPrecision(Precision{Places: 2}), WithMoney(Money{})}, op: OpAdd, input: "1", expectedErr: "invalid precision settings: amounts of money are rounded to their currency"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calculator := New(test.opts...)
+			result, err := calculator.Calculate(test.op, test.input)
+
+			var formula bytes.Buffer
+			streamed, streamErr := calculator.CalculateStream(test.op, strings.NewReader(test.input), &formula)
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				assert.EqualError(t, streamErr, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, result.String())
+			assert.Equal(t, test.total, result.Total.String())
+			assert.NoError(t, streamErr)
+			assert.Equal(t, test.expected, formula.String())
+			assert.Equal(t, test.total, streamed.Total.String())
+		})
+	}
+}
+
+func TestPrecisionExpression(t *testing.T) {
+	tests := []struct {
+		name      string
+		precision Precision
+		input     string
+		expected  string
+	}{
+		{name: "display only", precision: Precision{Places: 1}, input: "1.14 * 2", expected: "1.1 * 2 = 2.3"},
+		{name: "round terms", precision: Precision{Places: 1, RoundTerms: true}, input: "1.14 * 2", expected: "1.1 * 2 = 2.2"},
+		{name: "fixed", precision: Precision{Places: 2, Fixed: true}, input: "(1 + 2) / 3", expected: "(1.00 + 2.00) / 3.00 = 1.00"},
+		{name: "floor", precision: Precision{Places: 0, Rounding: RoundFloor}, input: "10 / 4", expected: "10 / 4 = 2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := New(WithPrecision(test.precision)).Evaluate(test.input)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, result.String())
+		})
+	}
+}
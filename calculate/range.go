@@ -0,0 +1,159 @@
+package calculate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// BoundAction is what happens to a term outside a Bound.
+type BoundAction string
+
+const (
+	// BoundExclude replaces the term with the identity for the operation,
+	// so it does not change the result.
+	BoundExclude BoundAction = "exclude"
+	// BoundClamp replaces the term with the bound.
+	BoundClamp BoundAction = "clamp"
+	// BoundError fails the calculation.
+	BoundError BoundAction = "error"
+	// BoundWarn keeps the term and adds a warning.
+	BoundWarn BoundAction = "warn"
+)
+
+var (
+	ErrOutOfRange   = errors.New("number out of range")
+	ErrInvalidRange = errors.New("invalid range")
+)
+
+// ParseBoundAction accepts the name of a BoundAction.
+func ParseBoundAction(name string) (BoundAction, error) {
+	action := BoundAction(strings.ToLower(strings.TrimSpace(name)))
+	switch action {
+	case BoundExclude, BoundClamp, BoundError, BoundWarn:
+		return action, nil
+	}
+	return "", fmt.Errorf("unknown bound action: %q", name)
+}
+
+// Bound is one end of a Range. An inclusive bound accepts the bound itself;
+// an exclusive one does not. An empty Action means BoundExclude.
+type Bound struct {
+	Value     decimal.Decimal
+	Exclusive bool
+	Action    BoundAction
+}
+
+func (b *Bound) action() BoundAction {
+	if b.Action == "" {
+		return BoundExclude
+	}
+	return b.Action
+}
+
+// Range limits the terms included in calculations. A nil bound leaves that
+// side unlimited.
+type Range struct {
+	Min *Bound
+	Max *Bound
+}
+
+// Validate reports a range that no number can satisfy, or bounds that
+// cannot be applied.
+func (r Range) Validate() error {
+	for _, side := range []struct {
+		name  string
+		bound *Bound
+	}{{"minimum", r.Min}, {"maximum", r.Max}} {
+		if side.bound == nil {
+			continue
+		}
+		switch side.bound.action() {
+		case BoundExclude, BoundClamp, BoundError, BoundWarn:
+		default:
+			return fmt.Errorf("%w: unknown action %q for the %s", ErrInvalidRange, side.bound.Action, side.name)
+		}
+		if side.bound.Exclusive && side.bound.action() == BoundClamp {
+			return fmt.Errorf("%w: cannot clamp to an exclusive %s", ErrInvalidRange, side.name)
+		}
+	}
+
+	if r.Min != nil && r.Max != nil {
+		cmp := r.Min.Value.Cmp(r.Max.Value)
+		if cmp > 0 || (cmp == 0 && (r.Min.Exclusive || r.Max.Exclusive)) {
+			return fmt.Errorf("%w: no number is within minimum %s and maximum %s", ErrInvalidRange, r.Min.Value, r.Max.Value)
+		}
+	}
+	return nil
+}
+
+// violation describes how a value falls outside a range.
+type violation struct {
+	bound  *Bound
+	reason DropReason
+	detail string
+}
+
+// check returns how value falls outside the range, or nil if it is within.
+func (r Range) check(value decimal.Decimal) *violation {
+	if r.Max != nil {
+		if cmp := value.Cmp(r.Max.Value); cmp > 0 || (cmp == 0 && r.Max.Exclusive) {
+			detail := fmt.Sprintf("greater than maximum %s", r.Max.Value)
+			if r.Max.Exclusive {
+				detail = fmt.Sprintf("not less than exclusive maximum %s", r.Max.Value)
+			}
+			return &violation{bound: r.Max, reason: DropReasonExceedsMax, detail: detail}
+		}
+	}
+	if r.Min != nil {
+		if cmp := value.Cmp(r.Min.Value); cmp < 0 || (cmp == 0 && r.Min.Exclusive) {
+			detail := fmt.Sprintf("less than minimum %s", r.Min.Value)
+			if r.Min.Exclusive {
+				detail = fmt.Sprintf("not greater than exclusive minimum %s", r.Min.Value)
+			}
+			return &violation{bound: r.Min, reason: DropReasonBelowMin, detail: detail}
+		}
+	}
+	return nil
+}
+
+// RangeError reports a term outside a bound whose action is BoundError.
+// Offset is the term's byte offset in the input.
+type RangeError struct {
+	Term   Term
+	Offset int
+	Reason DropReason
+	Detail string
+}
+
+func (e *RangeError) Error() string {
+	return fmt.Sprintf("%v: term %d (%s) is %s", ErrOutOfRange, e.Term.Index+1, e.Term.Value, e.Detail)
+}
+
+func (e *RangeError) Is(target error) bool {
+	return target == ErrOutOfRange
+}
+
+// applyRange checks value, the value of term after any negatives policy,
+// against the range. term was found at offset in the input. It returns the
+// value to calculate with: value itself, excluded for the identity, or
+// clamped to the bound. Terms left out are also returned as a DroppedTerm;
+// terms kept with a warning return the warning.
+func (c *Calculator) applyRange(term Term, value decimal.Decimal, offset int, identity decimal.Decimal) (decimal.Decimal, *DroppedTerm, string, error) {
+	v := c.valueRange.check(value)
+	if v == nil {
+		return value, nil, "", nil
+	}
+
+	switch v.bound.action() {
+	case BoundClamp:
+		return v.bound.Value, &DroppedTerm{Term: term, Reason: v.reason, Detail: v.detail, Replacement: v.bound.Value}, "", nil
+	case BoundError:
+		return decimal.Decimal{}, nil, "", &RangeError{Term: term, Offset: offset, Reason: v.reason, Detail: v.detail}
+	case BoundWarn:
+		return value, nil, fmt.Sprintf("term %d (%s) is %s", term.Index+1, value, v.detail), nil
+	}
+	return identity, &DroppedTerm{Term: term, Reason: v.reason, Detail: v.detail, Replacement: identity}, "", nil
+}
@@ -0,0 +1,146 @@
+package calculate
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func bound(value string, exclusive bool, action BoundAction) *Bound {
+	return &Bound{Value: decimal.RequireFromString(value), Exclusive: exclusive, Action: action}
+}
+
+func TestParseBoundAction(t *testing.T) {
+	for _, name := range []string{"exclude", "clamp", "error", "warn", " Clamp "} {
+		_, err := ParseBoundAction(name)
+		assert.NoError(t, err, name)
+	}
+	_, err := ParseBoundAction("skip")
+	assert.EqualError(t, err, `unknown bound action: "skip"`)
+}
+
+func TestRangeValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		r           Range
+		expectedErr string
+	}{
+		{name: "unbounded", r: Range{}},
+		{name: "default action", r: Range{Max: &Bound{Value: decimal.NewFromInt(10)}}},
+		{name: "single value", r: Range{Min: bound("5", false, BoundExclude), Max: bound("5", false, BoundClamp)}},
+		{name: "min above max", r: Range{Min: bound("6", false, BoundExclude), Max: bound("5", false, BoundExclude)}, expectedErr: "invalid range: no number is within minimum 6 and maximum 5"},
+		{name: "exclusive single value", r: Range{Min: bound("5", true, BoundExclude), Max: bound("5", false, BoundExclude)}, expectedErr: "invalid range: no number is within minimum 5 and maximum 5"},
+		{name: "clamp to exclusive bound", r: Range{Max: bound("5", true, BoundClamp)}, expectedErr: "invalid range: cannot clamp to an exclusive maximum"},
+		{name: "unknown action", r: Range{Min: bound("0", false, "skip")}, expectedErr: `invalid range: unknown action "skip" for the minimum`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.r.Validate()
+			if test.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, test.expectedErr)
+			assert.True(t, errors.Is(err, ErrInvalidRange))
+		})
+	}
+}
+
+func TestRangeActions(t *testing.T) {
+	tests := []struct {
+		name        string
+		r           Range
+		op          Operation
+		input       string
+		expected    string
+		warnings    []string
+		expectedErr string
+	}{
+		{name: "exclude above max", r: Range{Max: bound("10", false, BoundExclude)}, op: OpAdd, input: "5,10,11", expected: "5+10+0 = 15"},
+		{name: "exclude at exclusive max", r: Range{Max: bound("10", true, BoundExclude)}, op: OpAdd, input: "5,10,11", expected: "5+0+0 = 5"},
+		{name: "decimal max", r: Range{Max: bound("999.99", false, BoundExclude)}, op: OpAdd, input: "999.99,999.991", expected: "999.99+0 = 999.99"},
+		{name: "exclude below min", r: Range{Min: bound("2", false, BoundExclude)}, op: OpMultiply, input: "1,2,3", expected: "1*2*3 = 6"},
+		{name: "exclude at exclusive min", r: Range{Min: bound("2", true, BoundExclude)}, op: OpAdd, input: "1,2,3", expected: "0+0+3 = 3"},
+		{name: "clamp both ends", r: Range{Min: bound("2", false, BoundClamp), Max: bound("10", false, BoundClamp)}, op: OpAdd, input: "1,5,20", expected: "2+5+10 = 17"},
+		{name: "warn keeps the term", r: Range{Max: bound("10", false, BoundWarn)}, op: OpAdd, input: "5,11", expected: "5+11 = 16", warnings: []string{"term 2 (11) is greater than maximum 10"}},
+		{name: "warn at exclusive min", r: Range{Min: bound("0", true, BoundWarn)}, op: OpAdd, input: "0,1", expected: "0+1 = 1", warnings: []string{"term 1 (0) is not greater than exclusive minimum 0"}},
+		{name: "error above max", r: Range{Max: bound("10", false, BoundError)}, op: OpAdd, input: "5,11,12", expectedErr: "number out of range: term 2 (11) is greater than maximum 10"},
+		{name: "error below min", r: Range{Min: bound("1", false, BoundError)}, op: OpAdd, input: "5,0.5", expectedErr: "number out of range: term 2 (0.5) is less than minimum 1"},
+		{name: "invalid range", r: Range{Max: bound("10", true, BoundClamp)}, op: OpAdd, input: "1", expectedErr: "invalid range: cannot clamp to an exclusive maximum"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calculator := New(WithRange(test.r))
+			result, err := calculator.Calculate(test.op, test.input)
+
+			var formula bytes.Buffer
+			streamed, streamErr := calculator.CalculateStream(test.op, strings.NewReader(test.input), &formula)
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				assert.EqualError(t, streamErr, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, result.String())
+			assert.Equal(t, test.warnings, result.Warnings)
+
+			assert.NoError(t, streamErr)
+			assert.Equal(t, test.expected, formula.String())
+			assert.Equal(t, len(result.Dropped), streamed.DroppedCount)
+		})
+	}
+}
+
+func TestRangeResult(t *testing.T) {
+	calculator := New(WithMin(bound("2", false, BoundClamp)), WithMax(bound("10", false, BoundExclude)))
+	result, err := calculator.Add("1,5,11")
+	assert.NoError(t, err)
+	assert.Equal(t, []Term{{Index: 1, Value: decimal.NewFromInt(5)}}, result.Kept)
+	assert.Equal(t, []DroppedTerm{
+		{
+			Term:        Term{Index: 0, Value: decimal.NewFromInt(1)},
+			Reason:      DropReasonBelowMin,
+			Detail:      "less than minimum 2",
+			Replacement: decimal.NewFromInt(2),
+		},
+		{
+			Term:        Term{Index: 2, Value: decimal.NewFromInt(11)},
+			Reason:      DropReasonExceedsMax,
+			Detail:      "greater than maximum 10",
+			Replacement: decimal.Zero,
+		},
+	}, result.Dropped)
+
+	_, err = New(WithMax(bound("10", false, BoundError))).Add("1, 20")
+	var rangeErr *RangeError
+	assert.True(t, errors.As(err, &rangeErr))
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	assert.Equal(t, 3, rangeErr.Offset)
+	assert.Equal(t, DropReasonExceedsMax, rangeErr.Reason)
+
+	result, err = New(WithMax(nil)).Add("1,5000")
+	assert.NoError(t, err)
+	assert.Equal(t, "1+5000 = 5001", result.String())
+}
+
+func TestRangeExpressions(t *testing.T) {
+	result, err := New(WithMax(bound("10", false, BoundClamp))).Evaluate("2 * 20")
+	assert.NoError(t, err)
+	assert.Equal(t, "2 * 10 = 20", result.String())
+
+	result, err = New(WithMax(bound("10", false, BoundExclude))).Evaluate("2 * 20")
+	assert.NoError(t, err)
+	assert.Equal(t, "2 * 0 = 0", result.String())
+
+	_, err = New(WithMin(bound("1", false, BoundError))).Evaluate("2 + 0.5")
+	var rangeErr *RangeError
+	assert.True(t, errors.As(err, &rangeErr))
+	assert.Equal(t, 4, rangeErr.Offset)
+}
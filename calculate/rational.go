@@ -0,0 +1,148 @@
+package calculate
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"challenge-calculator/validate"
+)
+
+// RationalFormat is how the exact total of a rational calculation is shown.
+type RationalFormat string
+
+const (
+	// RationalFraction shows the total as a reduced fraction: 11/6.
+	RationalFraction RationalFormat = "fraction"
+	// RationalMixed shows the total as a mixed number: 1 5/6.
+	RationalMixed RationalFormat = "mixed"
+	// RationalDecimal shows the total as a decimal, rounded to the division
+	// precision: 1.8333333333333333.
+	RationalDecimal RationalFormat = "decimal"
+)
+
+// ErrInvalidRational is returned for rational settings that cannot be used.
+var ErrInvalidRational = errors.New("invalid rational settings")
+
+// ParseRationalFormat accepts the name of a RationalFormat.
+func ParseRationalFormat(name string) (RationalFormat, error) {
+	format := RationalFormat(strings.ToLower(strings.TrimSpace(name)))
+	switch format {
+	case RationalFraction, RationalMixed, RationalDecimal:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown rational format: %q", name)
+}
+
+// WithRational calculates exactly, with fractions, and accepts terms written
+// as fractions such as 2/3 or mixed numbers such as 1 1/2. The exact total
+// is Result.Fraction, shown in format, and Result.Total is rounded to the
+// division precision. Term values, which the range is checked against, are
+// rounded to 16 decimal places. It cannot be combined with WithMoney.
+func WithRational(format RationalFormat) Option {
+	return func(c *Calculator) {
+		c.rational = format
+		c.validatorOpts = append(c.validatorOpts, validate.WithFractions())
+	}
+}
+
+// validateRational reports rational settings that cannot be used.
+func (c *Calculator) validateRational() error {
+	if c.rational == "" {
+		return nil
+	}
+	if _, err := ParseRationalFormat(string(c.rational)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRational, err)
+	}
+	if c.money != nil {
+		return fmt.Errorf("%w: amounts of money cannot be fractions", ErrInvalidRational)
+	}
+	return nil
+}
+
+// exactValue returns the exact value to calculate with for term: the value
+// that replaced it if it was dropped.
+func exactValue(term Term, dropped *DroppedTerm) *big.Rat {
+	value := term.Fraction
+	if value == nil {
+		value = term.Value.Rat()
+	}
+	if dropped == nil {
+		return value
+	}
+	if dropped.Reason == DropReasonAbsolute {
+		return new(big.Rat).Abs(value)
+	}
+	return dropped.Replacement.Rat()
+}
+
+// applyExact applies op to total and value and stores the outcome in total,
+// as Operation.apply does for decimals.
+func applyExact(op Operation, total, value *big.Rat) error {
+	switch op {
+	case OpSubtract:
+		total.Sub(total, value)
+	case OpMultiply:
+		total.Mul(total, value)
+	case OpDivide:
+		if value.Sign() == 0 {
+			return ErrDivideByZero
+		}
+		total.Quo(total, value)
+	default:
+		total.Add(total, value)
+	}
+	return nil
+}
+
+// exactTotal applies op to the exact values of the terms of result.
+func exactTotal(op Operation, result *Result) (*big.Rat, error) {
+	total := new(big.Rat)
+	dropped := 0
+	for i, term := range result.Terms {
+		var droppedTerm *DroppedTerm
+		if dropped < len(result.Dropped) && result.Dropped[dropped].Index == term.Index {
+			droppedTerm = &result.Dropped[dropped]
+			dropped++
+		}
+		value := exactValue(term, droppedTerm)
+		if i == 0 {
+			total.Set(value)
+			continue
+		}
+		if err := applyExact(op, total, value); err != nil {
+			return nil, fmt.Errorf("%w: term %d is zero", err, term.Index+1)
+		}
+	}
+	return total, nil
+}
+
+// formatListTerm renders a term of a list formula for op. Fractions are
+// shown in parentheses between division signs, as in "(1/3)/(1/2)".
+func formatListTerm(op Operation, term Term, dropped *DroppedTerm, p *Precision) string {
+	if op == OpDivide && term.Fraction != nil && !term.Fraction.IsInt() && dropped == nil {
+		return "(" + term.text(p) + ")"
+	}
+	return formatTerm(term, dropped, p)
+}
+
+// formatExact renders an exact total in format. decimal is the rounded total
+// as shown for RationalDecimal.
+func formatExact(total *big.Rat, format RationalFormat, decimal string) string {
+	switch format {
+	case RationalDecimal:
+		return decimal
+	case RationalMixed:
+		if total.IsInt() || new(big.Int).Abs(total.Num()).Cmp(total.Denom()) < 0 {
+			return total.RatString()
+		}
+		whole, rest := new(big.Int).QuoRem(new(big.Int).Abs(total.Num()), total.Denom(), new(big.Int))
+		sign := ""
+		if total.Sign() < 0 {
+			sign = "-"
+		}
+		return fmt.Sprintf("%s%s %s/%s", sign, whole, rest, total.Denom())
+	}
+	return total.RatString()
+}
@@ -0,0 +1,124 @@
+package calculate
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"challenge-calculator/validate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRationalFormat(t *testing.T) {
+	format, err := ParseRationalFormat(" Mixed ")
+	assert.NoError(t, err)
+	assert.Equal(t, RationalMixed, format)
+
+	_, err = ParseRationalFormat("roman")
+	assert.EqualError(t, err, `unknown rational format: "roman"`)
+}
+
+func TestRational(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		op          Operation
+		input       string
+		expected    string
+		fraction    string
+		expectedErr string
+	}{
+		{name: "fraction", opts: []Option{WithRational(RationalFraction)}, op: OpAdd, input: "1/3, 2/3, 5/6", expected: "1/3+2/3+5/6 = 11/6", fraction: "11/6"},
+		{name: "mixed", opts: []Option{WithRational(RationalMixed)}, op: OpAdd, input: "1/3, 2/3, 5/6", expected: "1/3+2/3+5/6 = 1 5/6", fraction: "11/6"},
+		{name: "decimal", opts: []Option{WithRational(RationalDecimal)}, op: OpAdd, input: "1/3, 2/3, 5/6", expected: "1/3+2/3+5/6 = 1.8333333333333333", fraction: "11/6"},
+		{name: "decimal precision", opts: []Option{WithRational(RationalDecimal), WithDivisionPrecision(2)}, op: OpAdd, input: "1/3,1/3", expected: "1/3+1/3 = 0.67", fraction: "2/3"},
+		{name: "whole total", opts: []Option{WithRational(RationalMixed)}, op: OpAdd, input: "1/3,2/3", expected: "1/3+2/3 = 1", fraction: "1"},
+		{name: "proper fraction", opts: []Option{WithRational(RationalMixed)}, op: OpAdd, input: "1/6,1/6", expected: "1/6+1/6 = 1/3", fraction: "1/3"},
+		{name: "negative mixed", opts: []Option{WithRational(RationalMixed), WithAllowNegatives(true)}, op: OpSubtract, input: "1/4,1 1/2", expected: "1/4-3/2 = -1 1/4", fraction: "-5/4"},
+		{name: "decimals and fractions", opts: []Option{WithRational(RationalFraction)}, op: OpAdd, input: "0.1,1/10", expected: "0.1+1/10 = 1/5", fraction: "1/5"},
+		{name: "multiply", opts: []Option{WithRational(RationalFraction)}, op: OpMultiply, input: "2/3,3/4", expected: "2/3*3/4 = 1/2", fraction: "1/2"},
+		{name: "divide", opts: []Option{WithRational(RationalFraction)}, op: OpDivide, input: "1/3,1/2,2", expected: "(1/3)/(1/2)/2 = 1/3", fraction: "1/3"},
+		{name: "divide by zero", opts: []Option{WithRational(RationalFraction)}, op: OpDivide, input: "1/3,0/2", expectedErr: "division by zero: term 2 is zero"},
+		{name: "absolute value", opts: []Option{WithRational(RationalFraction), WithNegatives(validate.NegativesAbs)}, op: OpAdd, input: "1/3,-1/3", expected: "1/3+|-1/3| = 2/3", fraction: "2/3"},
+		{name: "ignored negative", opts: []Option{WithRational(RationalFraction), WithNegatives(validate.NegativesIgnore)}, op: OpAdd, input: "1/3,-1/3", expected: "1/3+[-1/3] = 1/3", fraction: "1/3"},
+		{name: "clamped", opts: []Option{WithRational(RationalFraction), WithMax(bound("1", false, BoundClamp))}, op: OpAdd, input: "1/3,7/2", expected: "1/3+1 = 4/3", fraction: "4/3"},
+		{name: "excluded", opts: []Option{WithRational(RationalFraction)}, op: OpAdd, input: "1/3,3001/3", expected: "1/3+0 = 1/3", fraction: "1/3"},
+		{name: "not enabled", op: OpAdd, input: "1/3,1", expected: "0+1 = 1"},
+		{name: "unknown format", opts: []Option{WithRational("roman")}, op: OpAdd, input: "1", expectedErr: `invalid rational settings: unknown rational format: "roman"`},
+		{name: "money", opts: []Option{WithRational(RationalFraction), WithMoney(Money{})}, op: OpAdd, input: "1", expectedErr: "invalid rational settings: amounts of money cannot be fractions"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calculator := New(test.opts...)
+			result, err := calculator.Calculate(test.op, test.input)
+
+			var formula bytes.Buffer
+			streamed, streamErr := calculator.CalculateStream(test.op, strings.NewReader(test.input), &formula)
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				assert.EqualError(t, streamErr, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, result.String())
+			assert.NoError(t, streamErr)
+			assert.Equal(t, test.expected, formula.String())
+			assert.True(t, result.Total.Equal(streamed.Total))
+			if test.fraction == "" {
+				assert.Nil(t, result.Fraction)
+				assert.Nil(t, streamed.Fraction)
+			} else {
+				assert.Equal(t, test.fraction, result.Fraction.RatString())
+				assert.Equal(t, test.fraction, streamed.Fraction.RatString())
+			}
+		})
+	}
+}
+
+func TestRationalResult(t *testing.T) {
+	result, err := New(WithRational(RationalMixed)).Add("1/3,2")
+	assert.NoError(t, err)
+	if assert.Len(t, result.Terms, 2) {
+		assert.Equal(t, "0.3333333333333333", result.Terms[0].Value.String())
+		assert.Equal(t, big.NewRat(1, 3), result.Terms[0].Fraction)
+		assert.Nil(t, result.Terms[1].Fraction)
+	}
+	assert.Equal(t, "2.3333333333333333", result.Total.String())
+
+	input := strings.Repeat("1/3,", 2*minParallelTerms) + "1/3"
+	parallel, err := New(WithRational(RationalFraction), WithWorkers(4)).Add(input)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewRat(2*minParallelTerms+1, 3), parallel.Fraction)
+}
+
+func TestRationalExpression(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      RationalFormat
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{name: "exact thirds", format: RationalFraction, input: "1 / 3 * 3", expected: "1 / 3 * 3 = 1"},
+		{name: "mixed", format: RationalMixed, input: "(1 + 1/2) * 3", expected: "(1 + 1 / 2) * 3 = 4 1/2"},
+		{name: "negated", format: RationalFraction, input: "-(2 / 3)", expected: "-(2 / 3) = -2/3"},
+		{name: "decimal", format: RationalDecimal, input: "2 / 3", expected: "2 / 3 = 0.6666666666666667"},
+		{name: "divide by zero", format: RationalFraction, input: "1 / (2 - 2)", expectedErr: "division by zero at column 3"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := New(WithRational(test.format)).Evaluate(test.input)
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, result.String())
+		})
+	}
+}
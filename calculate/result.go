@@ -0,0 +1,82 @@
+package calculate
+
+import (
+	"math/big"
+
+	"challenge-calculator/validate"
+
+	"github.com/shopspring/decimal"
+)
+
+type DropReason string
+
+const (
+	DropReasonExceedsMax DropReason = "exceeds_max"
+	DropReasonBelowMin   DropReason = "below_min"
+	DropReasonNegative   DropReason = "negative"
+	DropReasonAbsolute   DropReason = "absolute_value"
+)
+
+// Term is a single parsed number and its position in the input. Fraction is
+// the exact value of a term written as a fraction, which Value is rounded
+// from.
+type Term struct {
+	Index    int             `json:"index"`
+	Value    decimal.Decimal `json:"value"`
+	Fraction *big.Rat        `json:"fraction,omitempty"`
+}
+
+// text renders the value of the term as written, as a fraction if it was
+// one, or rounded to p.
+func (t Term) text(p *Precision) string {
+	if t.Fraction != nil {
+		return t.Fraction.RatString()
+	}
+	return p.format(t.Value)
+}
+
+// DroppedTerm is a term that was left out of the calculation. Replacement
+// is the value used in its place: the identity for the operation when the
+// term is excluded, or the bound it was clamped to.
+type DroppedTerm struct {
+	Term
+	Reason      DropReason      `json:"reason"`
+	Detail      string          `json:"detail"`
+	Replacement decimal.Decimal `json:"replacement"`
+}
+
+// Result is the outcome of a calculation. Terms holds every parsed number in
+// input order, split into the ones that were kept and the ones that were
+// dropped. InvalidTokens lists the tokens that were read as zero, and each
+// also produces a warning. Expression is only set for results of Evaluate.
+//
+// With WithMoney, Currency is the currency of the total, and Conversions
+// lists the terms converted to it from another currency. Those terms hold
+// the converted value.
+//
+// With WithRational, Fraction is the exact total, which Total is rounded
+// from. With WithPrecision, Total is rounded to the precision, and so are
+// Terms if it rounds them.
+type Result struct {
+	Operation     Operation                     `json:"operation,omitempty"`
+	Expression    string                        `json:"expression,omitempty"`
+	Terms         []Term                        `json:"terms"`
+	Kept          []Term                        `json:"kept"`
+	Dropped       []DroppedTerm                 `json:"dropped"`
+	Total         decimal.Decimal               `json:"total"`
+	Fraction      *big.Rat                      `json:"fraction,omitempty"`
+	Currency      string                        `json:"currency,omitempty"`
+	Conversions   []Conversion                  `json:"conversions,omitempty"`
+	Warnings      []string                      `json:"warnings"`
+	InvalidTokens []*validate.InvalidTokenError `json:"invalidTokens,omitempty"`
+
+	// rationalFormat is how FormulaFormatter shows Fraction, and precision
+	// how it shows numbers.
+	rationalFormat RationalFormat
+	precision      *Precision
+}
+
+// String renders the result with FormulaFormatter.
+func (r *Result) String() string {
+	return FormulaFormatter{}.Format(r)
+}
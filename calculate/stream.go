@@ -0,0 +1,190 @@
+package calculate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"time"
+
+	"challenge-calculator/logger"
+	"challenge-calculator/validate"
+
+	"github.com/shopspring/decimal"
+)
+
+// maxStreamWarnings is the most warnings about single terms a StreamResult
+// keeps. The rest are only counted, so memory use stays bounded.
+const maxStreamWarnings = 100
+
+// StreamResult is the outcome of CalculateStream. It keeps counts instead of
+// the terms themselves. Fraction is the exact total with WithRational.
+type StreamResult struct {
+	Operation      Operation       `json:"operation"`
+	Total          decimal.Decimal `json:"total"`
+	Fraction       *big.Rat        `json:"fraction,omitempty"`
+	Currency       string          `json:"currency,omitempty"`
+	TermCount      int             `json:"termCount"`
+	DroppedCount   int             `json:"droppedCount"`
+	ConvertedCount int             `json:"convertedCount,omitempty"`
+	InvalidCount   int             `json:"invalidCount"`
+	Warnings       []string        `json:"warnings"`
+}
+
+// CalculateStream applies op to the terms read from r as they are read, and
+// writes the formula to formula, in the form Result.String uses. Memory use
+// stays the same however long the input is, so only the first warnings about
+// single terms are kept.
+//
+// The formula is spooled to a temporary file as it is built and only copied
+// to formula once the calculation succeeds, so nothing is written when an
+// error is returned. Regular-expression delimiters are not supported.
+func (c *Calculator) CalculateStream(op Operation, r io.Reader, formula io.Writer) (*StreamResult, error) {
+	start := time.Now()
+	logger.DebugFields("Starting streamed calculation", logger.Fields{"operation": op})
+	result := &StreamResult{Operation: op, Total: decimal.Zero}
+
+	if err := c.valueRange.Validate(); err != nil {
+		return nil, err
+	}
+	var l *ledger
+	if c.money != nil {
+		if err := c.money.validateList(op); err != nil {
+			return nil, err
+		}
+		l = newLedger(c.money)
+	}
+	if err := c.validateRational(); err != nil {
+		return nil, err
+	}
+	if err := c.validatePrecision(); err != nil {
+		return nil, err
+	}
+	var exact *big.Rat
+	if c.rational != "" {
+		exact = new(big.Rat)
+	}
+
+	spool := io.Discard
+	if formula != io.Discard {
+		file, err := os.CreateTemp("", "calculate-formula-*")
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(file.Name())
+		defer file.Close()
+		spool = file
+	}
+	w := bufio.NewWriter(spool)
+
+	var termWarnings []string
+	omitted := 0
+	warn := func(warning string) {
+		if len(termWarnings) < maxStreamWarnings {
+			termWarnings = append(termWarnings, warning)
+		} else {
+			omitted++
+		}
+	}
+	warnings, err := c.validator.ParseStream(r, func(term validate.Value) error {
+		if term.Invalid != nil {
+			result.InvalidCount++
+			warn(fmt.Sprintf("invalid token %s, treated as 0", term.Invalid))
+		}
+		t := c.roundTerm(Term{Index: term.Index, Value: term.Value, Fraction: term.Fraction})
+		var conversion *Conversion
+		if l != nil {
+			var err error
+			if t.Value, conversion, err = l.convert(t, term.Currency, term.Offset); err != nil {
+				return err
+			}
+		}
+		value, dropped, warning, err := c.applyTerm(t, term.Offset, op.identity())
+		if err != nil {
+			return err
+		}
+		if dropped != nil {
+			result.DroppedCount++
+		}
+		if warning != "" {
+			warn(warning)
+		}
+
+		if term.Index > 0 {
+			w.WriteString(op.Symbol())
+		}
+		if conversion != nil {
+			result.ConvertedCount++
+			w.WriteString(formatConversion(formatTerm(t, dropped, nil), conversion))
+		} else {
+			w.WriteString(formatListTerm(op, t, dropped, c.precision))
+		}
+		result.TermCount++
+
+		if exact != nil {
+			if term.Index == 0 {
+				exact.Set(exactValue(t, dropped))
+			} else if err := applyExact(op, exact, exactValue(t, dropped)); err != nil {
+				return fmt.Errorf("%w: term %d is zero", err, term.Index+1)
+			}
+		}
+		if term.Index == 0 {
+			result.Total = value
+			return nil
+		}
+		result.Total, err = op.apply(result.Total, value, c.divisionPrecision)
+		if err != nil {
+			return fmt.Errorf("%w: term %d is zero", err, term.Index+1)
+		}
+		return nil
+	})
+	if err != nil {
+		logger.ErrorFields("Error calculating streamed result", logger.Fields{"error": err.Error()})
+		return nil, err
+	}
+	if l != nil {
+		result.Total = l.round(result.Total)
+		result.Currency = l.currency
+	}
+	total := formatTotal(result.Total, result.Currency)
+	if exact != nil || c.precision != nil {
+		result.Fraction = exact
+		result.Total = c.roundTotal(result.Total, exact)
+		total = c.precision.format(result.Total)
+	}
+	if exact != nil {
+		total = formatExact(exact, c.rational, total)
+	}
+	if err := c.checkTotal(result.Total); err != nil {
+		logger.ErrorFields("Error calculating streamed result", logger.Fields{"error": err.Error()})
+		return nil, err
+	}
+
+	result.Warnings = append(warnings, termWarnings...)
+	if omitted == 1 {
+		result.Warnings = append(result.Warnings, "1 more warning not shown")
+	} else if omitted > 1 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("%d more warnings not shown", omitted))
+	}
+	w.WriteString(" = " + total)
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	if file, ok := spool.(*os.File); ok {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(formula, file); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.DebugFields("Streamed calculation completed", logger.Fields{
+		"term_count":     result.TermCount,
+		"excluded_count": result.DroppedCount,
+		"invalid_count":  result.InvalidCount,
+		"duration_ms":    float64(time.Since(start).Microseconds()) / 1000,
+	})
+	return result, nil
+}
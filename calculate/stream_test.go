@@ -0,0 +1,79 @@
+package calculate
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"challenge-calculator/validate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateStreamMatchesCalculate(t *testing.T) {
+	inputs := []string{
+		"",
+		"1,2,3",
+		"//;\n1;2;3",
+		"1,abc,3",
+		"2,1001,3",
+		"1.5\n2.25",
+		strings.Repeat("7,", 1000) + "7",
+	}
+
+	for _, op := range []Operation{OpAdd, OpSubtract, OpMultiply} {
+		for _, input := range inputs {
+			expected, err := New().Calculate(op, input)
+			assert.NoError(t, err)
+
+			var formula bytes.Buffer
+			result, err := New().CalculateStream(op, strings.NewReader(input), &formula)
+			assert.NoError(t, err)
+			assert.Equal(t, expected.String(), formula.String(), "%s %q", op, input)
+			assert.True(t, expected.Total.Equal(result.Total), "%s %q", op, input)
+			assert.Equal(t, len(expected.Terms), result.TermCount)
+			assert.Equal(t, len(expected.Dropped), result.DroppedCount)
+			assert.Equal(t, len(expected.InvalidTokens), result.InvalidCount)
+		}
+	}
+}
+
+func TestCalculateStreamErrors(t *testing.T) {
+	var formula bytes.Buffer
+	_, err := New().CalculateStream(OpAdd, strings.NewReader("1,-2"), &formula)
+	assert.True(t, errors.Is(err, validate.ErrNegativeNumbers))
+	assert.Empty(t, formula.String())
+
+	_, err = New().CalculateStream(OpDivide, strings.NewReader("1,0"), &bytes.Buffer{})
+	assert.True(t, errors.Is(err, ErrDivideByZero))
+
+	result, err := New().CalculateStream(OpAdd, strings.NewReader("1,x,y"), &bytes.Buffer{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`invalid token "x" at index 1 (offset 2): not a number, treated as 0`,
+		`invalid token "y" at index 2 (offset 4): not a number, treated as 0`,
+	}, result.Warnings)
+}
+
+func TestCalculateStreamWarningLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		invalid  int
+		expected string
+	}{
+		{name: "at the limit", invalid: maxStreamWarnings, expected: `invalid token "x" at index 100 (offset 200): not a number, treated as 0`},
+		{name: "one more", invalid: maxStreamWarnings + 1, expected: "1 more warning not shown"},
+		{name: "several more", invalid: maxStreamWarnings + 5, expected: "5 more warnings not shown"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			input := "1" + strings.Repeat(",x", test.invalid)
+			result, err := New().CalculateStream(OpAdd, strings.NewReader(input), &bytes.Buffer{})
+			assert.NoError(t, err)
+			assert.Equal(t, test.invalid, result.InvalidCount)
+			assert.Equal(t, test.expected, result.Warnings[len(result.Warnings)-1])
+		})
+	}
+}
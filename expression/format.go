@@ -0,0 +1,62 @@
+package expression
+
+import (
+	"strings"
+)
+
+// Format renders node with only the parentheses needed to keep its
+// structure. render supplies the text for each literal, which lets callers
+// show substituted values.
+func Format(node Node, render func(*Number) string) string {
+	var sb strings.Builder
+	format(&sb, node, render)
+	return sb.String()
+}
+
+func format(sb *strings.Builder, node Node, render func(*Number) string) {
+	switch n := node.(type) {
+	case *Number:
+		sb.WriteString(render(n))
+	case *Unary:
+		sb.WriteString("-")
+		formatOperand(sb, n.Operand, render, precedence(n.Operand) <= precedence(n))
+	case *Binary:
+		formatOperand(sb, n.Left, render, precedence(n.Left) < precedence(n))
+		sb.WriteString(" ")
+		sb.WriteString(n.Op.symbol())
+		sb.WriteString(" ")
+		formatOperand(sb, n.Right, render, precedence(n.Right) <= precedence(n))
+	}
+}
+
+func formatOperand(sb *strings.Builder, node Node, render func(*Number) string, parens bool) {
+	if parens {
+		sb.WriteString("(")
+	}
+	format(sb, node, render)
+	if parens {
+		sb.WriteString(")")
+	}
+}
+
+func precedence(node Node) int {
+	switch n := node.(type) {
+	case *Number:
+		if n.Value.IsNegative() {
+			return 3
+		}
+		return 4
+	case *Unary:
+		return 3
+	case *Binary:
+		if n.Op == TokenStar || n.Op == TokenSlash {
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+func (k TokenKind) symbol() string {
+	return strings.Trim(k.String(), "'")
+}
@@ -0,0 +1,124 @@
+package expression
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+type TokenKind int
+
+const (
+	TokenEOF TokenKind = iota
+	TokenNumber
+	TokenPlus
+	TokenMinus
+	TokenStar
+	TokenSlash
+	TokenLParen
+	TokenRParen
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case TokenNumber:
+		return "number"
+	case TokenPlus:
+		return "'+'"
+	case TokenMinus:
+		return "'-'"
+	case TokenStar:
+		return "'*'"
+	case TokenSlash:
+		return "'/'"
+	case TokenLParen:
+		return "'('"
+	case TokenRParen:
+		return "')'"
+	default:
+		return "end of input"
+	}
+}
+
+// Token is a lexical token and its byte offset in the input.
+type Token struct {
+	Kind   TokenKind
+	Text   string
+	Offset int
+}
+
+// SyntaxError reports where in the input parsing failed. Column is 1-based
+// and counted in characters.
+type SyntaxError struct {
+	Offset int
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at column %d: %s", e.Column, e.Msg)
+}
+
+func newSyntaxError(input string, offset int, format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{
+		Offset: offset,
+		Column: Column(input, offset),
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+// Column returns the 1-based column, counted in characters, of the byte at
+// offset in input.
+func Column(input string, offset int) int {
+	return utf8.RuneCountInString(input[:offset]) + 1
+}
+
+var punctuation = map[byte]TokenKind{
+	'+': TokenPlus,
+	'-': TokenMinus,
+	'*': TokenStar,
+	'/': TokenSlash,
+	'(': TokenLParen,
+	')': TokenRParen,
+}
+
+// Tokenize splits input into tokens, ending with a TokenEOF.
+func Tokenize(input string) ([]Token, error) {
+	var tokens []Token
+	pos := 0
+	for pos < len(input) {
+		c := input[pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			pos++
+		case isDigit(c) || c == '.':
+			start := pos
+			seenPoint := false
+			for pos < len(input) && (isDigit(input[pos]) || input[pos] == '.') {
+				if input[pos] == '.' {
+					if seenPoint {
+						return nil, newSyntaxError(input, pos, "unexpected second decimal point")
+					}
+					seenPoint = true
+				}
+				pos++
+			}
+			if input[start:pos] == "." {
+				return nil, newSyntaxError(input, start, "decimal point without digits")
+			}
+			tokens = append(tokens, Token{Kind: TokenNumber, Text: input[start:pos], Offset: start})
+		default:
+			kind, ok := punctuation[c]
+			if !ok {
+				r, _ := utf8.DecodeRuneInString(input[pos:])
+				return nil, newSyntaxError(input, pos, "unexpected character %q", r)
+			}
+			tokens = append(tokens, Token{Kind: kind, Text: input[pos : pos+1], Offset: pos})
+			pos++
+		}
+	}
+	return append(tokens, Token{Kind: TokenEOF, Offset: len(input)}), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
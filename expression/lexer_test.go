@@ -0,0 +1,79 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    []Token
+		expectedErr string
+	}{
+		{
+			name:  "operators and numbers",
+			input: "(1.5 + 2)*3",
+			expected: []Token{
+				{Kind: TokenLParen, Text: "(", Offset: 0},
+				{Kind: TokenNumber, Text: "1.5", Offset: 1},
+				{Kind: TokenPlus, Text: "+", Offset: 5},
+				{Kind: TokenNumber, Text: "2", Offset: 7},
+				{Kind: TokenRParen, Text: ")", Offset: 8},
+				{Kind: TokenStar, Text: "*", Offset: 9},
+				{Kind: TokenNumber, Text: "3", Offset: 10},
+				{Kind: TokenEOF, Offset: 11},
+			},
+		},
+		{
+			name:  "leading and trailing decimal points",
+			input: ".5-5.",
+			expected: []Token{
+				{Kind: TokenNumber, Text: ".5", Offset: 0},
+				{Kind: TokenMinus, Text: "-", Offset: 2},
+				{Kind: TokenNumber, Text: "5.", Offset: 3},
+				{Kind: TokenEOF, Offset: 5},
+			},
+		},
+		{
+			name:        "unexpected character",
+			input:       "1 + x",
+			expectedErr: "syntax error at column 5: unexpected character 'x'",
+		},
+		{
+			name:        "column counts characters not bytes",
+			input:       "1 € 2",
+			expectedErr: "syntax error at column 3: unexpected character '€'",
+		},
+		{
+			name:        "second decimal point",
+			input:       "1.2.3",
+			expectedErr: "syntax error at column 4: unexpected second decimal point",
+		},
+		{
+			name:        "lone decimal point",
+			input:       "1 + .",
+			expectedErr: "syntax error at column 5: decimal point without digits",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Tokenize(test.input)
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, result)
+			}
+		})
+	}
+}
+
+func TestColumn(t *testing.T) {
+	assert.Equal(t, 1, Column("1 + 2", 0))
+	assert.Equal(t, 5, Column("1 + 2", 4))
+	assert.Equal(t, 3, Column("1€/0", 4))
+}
@@ -0,0 +1,192 @@
+package expression
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Node is an element of a parsed expression.
+type Node interface {
+	Offset() int
+}
+
+// Number is a numeric literal. A unary minus written directly in front of a
+// literal is folded into it, so "-3" is a single negative Number.
+type Number struct {
+	Index int
+	Text  string
+	Value decimal.Decimal
+	Pos   int
+}
+
+// Unary is a sign applied to a parenthesised group or another unary node.
+type Unary struct {
+	Op      TokenKind
+	Operand Node
+	Pos     int
+}
+
+type Binary struct {
+	Op          TokenKind
+	Left, Right Node
+	Pos         int
+}
+
+func (n *Number) Offset() int { return n.Pos }
+func (n *Unary) Offset() int  { return n.Pos }
+func (n *Binary) Offset() int { return n.Pos }
+
+// Parse builds an expression tree from input using the usual precedence:
+// unary signs bind tightest, then * and /, then + and -. Operators of equal
+// precedence associate to the left.
+//
+//	expr    := term (('+' | '-') term)*
+//	term    := unary (('*' | '/') unary)*
+//	unary   := ('+' | '-') unary | primary
+//	primary := number | '(' expr ')'
+func Parse(input string) (Node, error) {
+	tokens, err := Tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{input: input, tokens: tokens}
+	node, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.Kind != TokenEOF {
+		return nil, newSyntaxError(input, tok.Offset, "unexpected %s", tok.Kind)
+	}
+	return node, nil
+}
+
+// Numbers returns the literals of an expression in the order they appear.
+func Numbers(node Node) []*Number {
+	var numbers []*Number
+	walk(node, func(n *Number) {
+		numbers = append(numbers, n)
+	})
+	return numbers
+}
+
+func walk(node Node, visit func(*Number)) {
+	switch n := node.(type) {
+	case *Number:
+		visit(n)
+	case *Unary:
+		walk(n.Operand, visit)
+	case *Binary:
+		walk(n.Left, visit)
+		walk(n.Right, visit)
+	}
+}
+
+type parser struct {
+	input   string
+	tokens  []Token
+	pos     int
+	numbers int
+}
+
+func (p *parser) peek() Token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() Token {
+	tok := p.tokens[p.pos]
+	if tok.Kind != TokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) parseExpr() (Node, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().Kind == TokenPlus || p.peek().Kind == TokenMinus {
+		op := p.next()
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: op.Kind, Left: left, Right: right, Pos: op.Offset}
+	}
+	return left, nil
+}
+
+func (p *parser) parseTerm() (Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().Kind == TokenStar || p.peek().Kind == TokenSlash {
+		op := p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: op.Kind, Left: left, Right: right, Pos: op.Offset}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	tok := p.peek()
+	if tok.Kind != TokenPlus && tok.Kind != TokenMinus {
+		return p.parsePrimary()
+	}
+	p.next()
+
+	if tok.Kind == TokenMinus && p.peek().Kind == TokenNumber {
+		number := p.newNumber(p.next())
+		number.Text = "-" + number.Text
+		number.Value = number.Value.Neg()
+		number.Pos = tok.Offset
+		return number, nil
+	}
+
+	operand, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	if tok.Kind == TokenPlus {
+		return operand, nil
+	}
+	return &Unary{Op: tok.Kind, Operand: operand, Pos: tok.Offset}, nil
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.next()
+	switch tok.Kind {
+	case TokenNumber:
+		return p.newNumber(tok), nil
+	case TokenLParen:
+		node, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.Kind != TokenRParen {
+			return nil, newSyntaxError(p.input, closing.Offset, "expected ')' to close '(' at column %d, found %s", Column(p.input, tok.Offset), closing.Kind)
+		}
+		return node, nil
+	default:
+		return nil, newSyntaxError(p.input, tok.Offset, "expected number or '(', found %s", tok.Kind)
+	}
+}
+
+func (p *parser) newNumber(tok Token) *Number {
+	text := tok.Text
+	if strings.HasPrefix(text, ".") {
+		text = "0" + text
+	}
+	// The lexer only produces digits and a single point, so this cannot fail.
+	value := decimal.RequireFromString(strings.TrimSuffix(text, "."))
+
+	number := &Number{Index: p.numbers, Text: tok.Text, Value: value, Pos: tok.Offset}
+	p.numbers++
+	return number
+}
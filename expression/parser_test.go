@@ -0,0 +1,68 @@
+package expression
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func renderText(n *Number) string {
+	return n.Text
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{name: "single number", input: "42", expected: "42"},
+		{name: "multiplication before addition", input: "1 + 2 * 3", expected: "1 + 2 * 3"},
+		{name: "parentheses kept where needed", input: "(1.5 + 2) * 3 - 4 / 2", expected: "(1.5 + 2) * 3 - 4 / 2"},
+		{name: "redundant parentheses dropped", input: "((1)) + (2 * 3)", expected: "1 + 2 * 3"},
+		{name: "left associative subtraction", input: "10 - 4 - 3", expected: "10 - 4 - 3"},
+		{name: "grouped right operand", input: "10 - (4 - 3)", expected: "10 - (4 - 3)"},
+		{name: "negative literal", input: "3 * -5", expected: "3 * -5"},
+		{name: "negated group", input: "-(1 + 2)", expected: "-(1 + 2)"},
+		{name: "double negation", input: "--5", expected: "-(-5)"},
+		{name: "unary plus", input: "+5 + +(2)", expected: "5 + 2"},
+		{name: "empty input", input: "", expectedErr: "syntax error at column 1: expected number or '(', found end of input"},
+		{name: "missing operand", input: "1 +", expectedErr: "syntax error at column 4: expected number or '(', found end of input"},
+		{name: "unclosed parenthesis", input: "(1 + 2", expectedErr: "syntax error at column 7: expected ')' to close '(' at column 1, found end of input"},
+		{name: "unexpected closing parenthesis", input: "1 + 2)", expectedErr: "syntax error at column 6: unexpected ')'"},
+		{name: "adjacent numbers", input: "1 2", expectedErr: "syntax error at column 3: unexpected number"},
+		{name: "operator without left operand", input: "* 2", expectedErr: "syntax error at column 1: expected number or '(', found '*'"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			node, err := Parse(test.input)
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				var syntaxErr *SyntaxError
+				assert.True(t, errors.As(err, &syntaxErr))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, Format(node, renderText))
+			}
+		})
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	node, err := Parse("(1 + -2) * 3 - .5")
+	assert.NoError(t, err)
+
+	numbers := Numbers(node)
+	texts := make([]string, len(numbers))
+	for i, number := range numbers {
+		assert.Equal(t, i, number.Index)
+		texts[i] = number.Text
+	}
+	assert.Equal(t, []string{"1", "-2", "3", ".5"}, texts)
+	assert.Equal(t, "-2", numbers[1].Value.String())
+	assert.Equal(t, 5, numbers[1].Offset())
+	assert.Equal(t, "0.5", numbers[3].Value.String())
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"challenge-calculator/calculate"
+	"challenge-calculator/expression"
+	"challenge-calculator/validate"
+)
+
+// errorOffsets returns the byte offsets in the calculator input that err
+// points at, or nil if it carries no position.
+func errorOffsets(err error) []int {
+	var negativeErr *validate.NegativeNumbersError
+	var delimiterErr *validate.DelimiterSyntaxError
+	var tokensErr *validate.InvalidTokensError
+	var ambiguousErr *validate.AmbiguousDelimiterError
+	var syntaxErr *expression.SyntaxError
+	var rangeErr *calculate.RangeError
+	var currencyErr *calculate.CurrencyError
+
+	switch {
+	case errors.As(err, &negativeErr):
+		return negativeErr.Positions
+	case errors.As(err, &delimiterErr):
+		return []int{delimiterErr.Offset}
+	case errors.As(err, &tokensErr):
+		return tokensErr.Offsets()
+	case errors.As(err, &ambiguousErr):
+		if ambiguousErr.Offset < 0 {
+			return nil
+		}
+		return []int{ambiguousErr.Offset}
+	case errors.As(err, &syntaxErr):
+		return []int{syntaxErr.Offset}
+	case errors.As(err, &rangeErr):
+		return []int{rangeErr.Offset}
+	case errors.As(err, &currencyErr):
+		return []int{currencyErr.Offset}
+	}
+	return nil
+}
+
+// lineOffset maps an offset in the calculator input back to the text as it
+// was typed, accounting for an operation prefix and escaped newlines.
+func lineOffset(line string, offset int) int {
+	if *mode == "expression" {
+		return offset
+	}
+
+	_, input, _ := calculate.SplitOperationPrefix(line)
+	if !shouldUnescape() {
+		return len(line) - len(input) + offset
+	}
+	unescaped := validate.UnescapeNewline(input)
+	if offset > len(unescaped) {
+		offset = len(unescaped)
+	}
+	return len(line) - len(input) + offset + strings.Count(unescaped[:offset], "\n")
+}
+
+// caretLine returns a line with a caret under each offset of line. Tabs are
+// copied so the carets stay aligned when printed under the line.
+func caretLine(line string, offsets []int) string {
+	sorted := append([]int{}, offsets...)
+	sort.Ints(sorted)
+
+	var sb strings.Builder
+	pos := 0
+	for _, offset := range sorted {
+		if offset < pos || offset > len(line) {
+			continue
+		}
+		for _, r := range line[pos:offset] {
+			if r == '\t' {
+				sb.WriteRune('\t')
+			} else {
+				sb.WriteRune(' ')
+			}
+		}
+		sb.WriteRune('^')
+		pos = offset
+		if pos < len(line) {
+			_, size := utf8.DecodeRuneInString(line[pos:])
+			pos += size
+		}
+	}
+	return sb.String()
+}
+
+// highlightError returns the text with carets under the positions err
+// points at, or an empty string if err has no position. Text spanning
+// several lines is shown one line at a time, and only the lines with a
+// position are included.
+func highlightError(text string, err error) string {
+	offsets := errorOffsets(err)
+	if len(offsets) == 0 {
+		return ""
+	}
+
+	textOffsets := make([]int, len(offsets))
+	for i, offset := range offsets {
+		textOffsets[i] = lineOffset(text, offset)
+	}
+
+	var highlighted []string
+	start := 0
+	for _, line := range strings.Split(text, "\n") {
+		var lineOffsets []int
+		for _, offset := range textOffsets {
+			if offset >= start && offset <= start+len(line) {
+				lineOffsets = append(lineOffsets, offset-start)
+			}
+		}
+		if len(lineOffsets) > 0 {
+			highlighted = append(highlighted, line, caretLine(line, lineOffsets))
+		}
+		start += len(line) + 1
+	}
+	return strings.Join(highlighted, "\n")
+}
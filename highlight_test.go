@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"challenge-calculator/validate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaretLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		offsets  []int
+		expected string
+	}{
+		{name: "single offset", line: "1,2,-3", offsets: []int{4}, expected: "    ^"},
+		{name: "multiple offsets out of order", line: "-1,2,-3", offsets: []int{5, 0}, expected: "^    ^"},
+		{name: "tabs are preserved", line: "\t1,x", offsets: []int{3}, expected: "\t  ^"},
+		{name: "multi-byte characters take one column", line: "€1,x", offsets: []int{5}, expected: "   ^"},
+		{name: "offset at end of line", line: "1,", offsets: []int{2}, expected: "  ^"},
+		{name: "offset past end ignored", line: "1", offsets: []int{5}, expected: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, caretLine(test.line, test.offsets))
+		})
+	}
+}
+
+func TestHighlightError(t *testing.T) {
+	*mode = "list"
+
+	tests := []struct {
+		name     string
+		line     string
+		expected string
+	}{
+		{
+			name:     "negative number",
+			line:     "1,2,-3",
+			expected: "1,2,-3\n    ^",
+		},
+		{
+			name:     "escaped newline in custom delimiter header",
+			line:     `//;\n1;-2`,
+			expected: "//;\\n1;-2\n       ^",
+		},
+		{
+			name:     "operation prefix",
+			line:     "mul:4,-1",
+			expected: "mul:4,-1\n      ^",
+		},
+		{
+			name:     "unclosed delimiter bracket",
+			line:     `//[*\n1*2`,
+			expected: "//[*\\n1*2\n  ^",
+		},
+		{
+			name:     "ambiguous custom delimiter",
+			line:     `//[x-]\n1x-2`,
+			expected: "//[x-]\\n1x-2\n   ^",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calculator, err := newCalculator()
+			assert.NoError(t, err)
+			_, err = calculateLine(calculator, "add", test.line)
+			assert.Error(t, err)
+			assert.Equal(t, test.expected, highlightError(test.line, err))
+		})
+	}
+
+	assert.Equal(t, "", highlightError("1,2", errors.New("no position")))
+
+	*maxAction = "error"
+	calculator, err := newCalculator()
+	assert.NoError(t, err)
+	_, err = calculateLine(calculator, "add", "1,2000,3")
+	assert.Equal(t, "1,2000,3\n  ^", highlightError("1,2000,3", err))
+	*maxAction = "exclude"
+
+	*money = true
+	calculator, err = newCalculator()
+	assert.NoError(t, err)
+	_, err = calculateLine(calculator, "add", "$1,2 EUR")
+	assert.Equal(t, "$1,2 EUR\n   ^", highlightError("$1,2 EUR", err))
+	*money = false
+
+	*multiline = true
+	defer func() { *multiline = false }()
+	calculator, err = newCalculator()
+	assert.NoError(t, err)
+	text := "//;\n1;2\n-3;4;-5"
+	_, err = calculateLine(calculator, "add", text)
+	assert.Equal(t, "-3;4;-5\n^    ^", highlightError(text, err))
+	assert.NotEmpty(t, highlightError("1,x", &validate.InvalidTokensError{Tokens: []*validate.InvalidTokenError{{Offset: 2}}}))
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// maxLineLength is the longest line a lineReader holds in memory. Longer
+// lines are streamed.
+var maxLineLength = 64 * 1024
+
+// record is one calculation read from the input, starting on line. A line
+// too long to hold in memory has no text; stream reads it instead and must
+// be drained or abandoned before the next call to Next.
+type record struct {
+	text   string
+	line   int
+	stream io.Reader
+}
+
+// recordReader splits the input into the text of each calculation.
+type recordReader interface {
+	// Next returns the next record. ok is false once the input is exhausted.
+	Next() (rec record, ok bool)
+	// Err returns the first error encountered while reading.
+	Err() error
+}
+
+// newRecordReader returns a reader for one record per line, or for records
+// spanning several lines when multiline is set. Only single-line records can
+// be streamed; when stream is false long lines are read whole.
+func newRecordReader(r io.Reader, multiline bool, terminator string, stream bool) recordReader {
+	reader := bufio.NewReaderSize(r, maxLineLength)
+	if multiline {
+		return &blockReader{reader: reader, terminator: terminator}
+	}
+	return &lineReader{reader: reader, stream: stream}
+}
+
+// readLine reads a whole line of any length without its line ending, like
+// bufio.ScanLines. ok is false at the end of the input or on error.
+func readLine(reader *bufio.Reader) (string, bool, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", false, err
+	}
+	if line == "" && err == io.EOF {
+		return "", false, nil
+	}
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r"), true, nil
+}
+
+// lineReader reads one record per line.
+type lineReader struct {
+	reader  *bufio.Reader
+	stream  bool
+	line    int
+	err     error
+	current *lineStream
+}
+
+func (r *lineReader) Next() (record, bool) {
+	if r.current != nil {
+		// Skip whatever the previous streamed line did not read.
+		if _, err := io.Copy(io.Discard, r.current); err != nil {
+			r.err = err
+			return record{}, false
+		}
+		r.current = nil
+	}
+
+	if !r.stream {
+		text, ok, err := readLine(r.reader)
+		if !ok {
+			r.err = err
+			return record{}, false
+		}
+		r.line++
+		return record{text: text, line: r.line}, true
+	}
+
+	line, err := r.reader.ReadSlice('\n')
+	switch {
+	case err == bufio.ErrBufferFull:
+		r.line++
+		r.current = &lineStream{pending: append([]byte(nil), line...), reader: r.reader}
+		return record{line: r.line, stream: r.current}, true
+	case err == io.EOF && len(line) == 0:
+		return record{}, false
+	case err != nil && err != io.EOF:
+		r.err = err
+		return record{}, false
+	}
+
+	r.line++
+	text := strings.TrimSuffix(string(line), "\n")
+	return record{text: strings.TrimSuffix(text, "\r"), line: r.line}, true
+}
+
+func (r *lineReader) Err() error {
+	return r.err
+}
+
+// lineStream reads the rest of a long line: first the pending bytes already
+// taken from reader, then reader up to the next newline. Like
+// bufio.ScanLines it drops the newline and a carriage return before it.
+type lineStream struct {
+	pending []byte
+	reader  *bufio.Reader
+	done    bool
+	err     error
+}
+
+func (l *lineStream) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) && !l.done {
+		c, ok := l.next()
+		if !ok {
+			break
+		}
+		if c == '\n' {
+			l.done = true
+			break
+		}
+		if c == '\r' && l.atLineEnd() {
+			continue
+		}
+		p[n] = c
+		n++
+
+		// Return what is already read rather than wait for more input.
+		if len(l.pending) == 0 && l.reader.Buffered() == 0 {
+			break
+		}
+	}
+
+	if n == 0 && l.done {
+		if l.err != nil {
+			return 0, l.err
+		}
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+func (l *lineStream) next() (byte, bool) {
+	if len(l.pending) > 0 {
+		c := l.pending[0]
+		l.pending = l.pending[1:]
+		return c, true
+	}
+	c, err := l.reader.ReadByte()
+	if err != nil {
+		l.done = true
+		if err != io.EOF {
+			l.err = err
+		}
+		return 0, false
+	}
+	return c, true
+}
+
+// atLineEnd reports whether the next byte ends the line.
+func (l *lineStream) atLineEnd() bool {
+	if len(l.pending) > 0 {
+		return l.pending[0] == '\n'
+	}
+	next, err := l.reader.Peek(1)
+	return err != nil || next[0] == '\n'
+}
+
+// blockReader reads records that span several lines. Without a terminator a
+// record ends at a blank line; with one it ends at the line ending with the
+// terminator, and may contain blank lines. The end of the input also ends a
+// record. Blank lines before a record are skipped.
+type blockReader struct {
+	reader     *bufio.Reader
+	terminator string
+	line       int
+	err        error
+}
+
+func (r *blockReader) Next() (record, bool) {
+	var lines []string
+	start := 0
+	for {
+		line, ok, err := readLine(r.reader)
+		if !ok {
+			r.err = err
+			break
+		}
+		r.line++
+		blank := strings.TrimSpace(line) == ""
+		if len(lines) == 0 {
+			if blank {
+				continue
+			}
+			start = r.line
+		}
+
+		if r.terminator == "" {
+			if blank {
+				return record{text: strings.Join(lines, "\n"), line: start}, true
+			}
+		} else if trimmed := strings.TrimRight(line, " \t"); strings.HasSuffix(trimmed, r.terminator) {
+			lines = append(lines, strings.TrimSuffix(trimmed, r.terminator))
+			return record{text: strings.Join(lines, "\n"), line: start}, true
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 0 {
+		return record{}, false
+	}
+	return record{text: strings.Join(lines, "\n"), line: start}, true
+}
+
+func (r *blockReader) Err() error {
+	return r.err
+}
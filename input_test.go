@@ -0,0 +1,101 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readAll(r recordReader) []record {
+	var records []record
+	for {
+		rec, ok := r.Next()
+		if !ok {
+			return records
+		}
+		if rec.stream != nil {
+			text, _ := io.ReadAll(rec.stream)
+			rec.text, rec.stream = "streamed:"+string(text), nil
+		}
+		records = append(records, rec)
+	}
+}
+
+func TestRecordReader(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		multiline  bool
+		terminator string
+		expected   []record
+	}{
+		{
+			name:     "one record per line",
+			input:    "1,2\n\n3\n",
+			expected: []record{{text: "1,2", line: 1}, {text: "", line: 2}, {text: "3", line: 3}},
+		},
+		{
+			name:      "records end at blank lines",
+			input:     "//;\n1;2;3\n\n\n4\n5\n",
+			multiline: true,
+			expected:  []record{{text: "//;\n1;2;3", line: 1}, {text: "4\n5", line: 5}},
+		},
+		{
+			name:      "last record ends with the input",
+			input:     "1\r\n2",
+			multiline: true,
+			expected:  []record{{text: "1\n2", line: 1}},
+		},
+		{
+			name:       "records end at the terminator",
+			input:      "//;\n1;2\n\n3;;\n;;\n4 ;; \n",
+			multiline:  true,
+			terminator: ";;",
+			expected:   []record{{text: "//;\n1;2\n\n3", line: 1}, {text: "", line: 5}, {text: "4 ", line: 6}},
+		},
+		{
+			name:       "literal backslash n is kept",
+			input:      `1\n2` + "\n",
+			multiline:  true,
+			terminator: ";;",
+			expected:   []record{{text: `1\n2`, line: 1}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader := newRecordReader(strings.NewReader(test.input), test.multiline, test.terminator, true)
+			assert.Equal(t, test.expected, readAll(reader))
+			assert.NoError(t, reader.Err())
+		})
+	}
+}
+
+func TestLongLinesAreStreamed(t *testing.T) {
+	defer func(length int) { maxLineLength = length }(maxLineLength)
+	maxLineLength = 16
+
+	long := strings.Repeat("1,", 20) + "1"
+	input := "1,2\r\n" + long + "\r\n" + long + "\n3"
+
+	reader := newRecordReader(strings.NewReader(input), false, "", true)
+	assert.Equal(t, []record{
+		{text: "1,2", line: 1},
+		{text: "streamed:" + long, line: 2},
+		{text: "streamed:" + long, line: 3},
+		{text: "3", line: 4},
+	}, readAll(reader))
+
+	// A streamed line that is not read is skipped.
+	reader = newRecordReader(strings.NewReader(long+"\n3\n"), false, "", true)
+	rec, _ := reader.Next()
+	assert.NotNil(t, rec.stream)
+	rec, _ = reader.Next()
+	assert.Equal(t, "3", rec.text)
+
+	// Without streaming, long lines are read whole.
+	reader = newRecordReader(strings.NewReader(long+"\n"), false, "", false)
+	assert.Equal(t, []record{{text: long, line: 1}}, readAll(reader))
+}
@@ -0,0 +1,20 @@
+// Package chunk splits work between goroutines.
+package chunk
+
+// Ranges splits n items into at most workers contiguous ranges of at least
+// minSize items each, returned as [start, end) pairs.
+func Ranges(n, workers, minSize int) [][2]int {
+	count := n / minSize
+	if count > workers {
+		count = workers
+	}
+	if count < 1 {
+		count = 1
+	}
+
+	ranges := make([][2]int, count)
+	for i := range ranges {
+		ranges[i] = [2]int{i * n / count, (i + 1) * n / count}
+	}
+	return ranges
+}
@@ -0,0 +1,28 @@
+package chunk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        int
+		workers  int
+		minSize  int
+		expected [][2]int
+	}{
+		{name: "too small to split", n: 10, workers: 4, minSize: 8, expected: [][2]int{{0, 10}}},
+		{name: "limited by size", n: 20, workers: 4, minSize: 8, expected: [][2]int{{0, 10}, {10, 20}}},
+		{name: "limited by workers", n: 100, workers: 3, minSize: 8, expected: [][2]int{{0, 33}, {33, 66}, {66, 100}}},
+		{name: "empty", n: 0, workers: 4, minSize: 8, expected: [][2]int{{0, 0}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, Ranges(test.n, test.workers, test.minSize))
+		})
+	}
+}
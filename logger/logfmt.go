@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// logfmtWriter turns zerolog's JSON records into logfmt lines, with the time,
+// level and message first and the remaining fields sorted by key.
+type logfmtWriter struct {
+	out io.Writer
+}
+
+var logfmtLeadingKeys = []string{zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.MessageFieldName}
+
+func (w *logfmtWriter) Write(p []byte) (int, error) {
+	decoder := json.NewDecoder(bytes.NewReader(p))
+	decoder.UseNumber()
+
+	var record map[string]interface{}
+	if err := decoder.Decode(&record); err != nil {
+		return 0, fmt.Errorf("decoding log record: %w", err)
+	}
+
+	var parts []string
+	for _, key := range logfmtLeadingKeys {
+		if value, ok := record[key]; ok {
+			parts = append(parts, key+"="+logfmtValue(value))
+			delete(record, key)
+		}
+	}
+
+	keys := make([]string, 0, len(record))
+	for key := range record {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parts = append(parts, key+"="+logfmtValue(record[key]))
+	}
+
+	if _, err := io.WriteString(w.out, strings.Join(parts, " ")+"\n"); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func logfmtValue(value interface{}) string {
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case json.Number:
+		return v.String()
+	case nil:
+		return ""
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			text = fmt.Sprint(v)
+		} else {
+			text = string(encoded)
+		}
+	}
+
+	if text == "" || strings.ContainsAny(text, " =\"\t\n") {
+		return fmt.Sprintf("%q", text)
+	}
+	return text
+}
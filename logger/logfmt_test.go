@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogfmtWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		record   string
+		expected string
+	}{
+		{
+			name:     "leading keys first, others sorted",
+			record:   `{"zeta":1,"message":"done","level":"info","alpha":"a","time":"2024-01-01T00:00:00Z"}`,
+			expected: "time=2024-01-01T00:00:00Z level=info message=done alpha=a zeta=1\n",
+		},
+		{
+			name:     "values needing quotes",
+			record:   `{"level":"error","message":"bad input","input":"a=b","empty":""}`,
+			expected: "level=error message=\"bad input\" empty=\"\" input=\"a=b\"\n",
+		},
+		{
+			name:     "numbers and booleans keep their form",
+			record:   `{"level":"debug","duration_ms":0.125,"strict":true}`,
+			expected: "level=debug duration_ms=0.125 strict=true\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := &logfmtWriter{out: &buf}
+			n, err := w.Write([]byte(test.record))
+			assert.NoError(t, err)
+			assert.Equal(t, len(test.record), n)
+			assert.Equal(t, test.expected, buf.String())
+		})
+	}
+
+	_, err := (&logfmtWriter{out: &bytes.Buffer{}}).Write([]byte("not json"))
+	assert.Error(t, err)
+}
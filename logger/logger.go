@@ -2,15 +2,30 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"time"
 
+	"github.com/mattn/go-isatty"
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
 )
 
 var Logger zerolog.Logger
 
+// Results go to userOut and per-line errors to userErr, so output piped to
+// other programs only contains results. Log records go wherever SetOutput
+// points them, which is stderr unless changed.
+var (
+	userOut io.Writer = os.Stdout
+	userErr io.Writer = os.Stderr
+)
+
+// Current log destination and format, kept so either can be changed alone.
+var (
+	output io.Writer = os.Stderr
+	format           = FormatConsole
+)
+
 type LogLevel string
 
 const (
@@ -19,16 +34,79 @@ const (
 	LogLevelError LogLevel = "error"
 )
 
+// Format selects how log records are written.
+type Format string
+
+const (
+	FormatConsole Format = "console"
+	FormatJSON    Format = "json"
+	FormatLogfmt  Format = "logfmt"
+)
+
+// Fields are attached to a log record as structured key/value pairs.
+type Fields map[string]interface{}
+
 func init() {
 	zerolog.TimeFieldFormat = time.RFC3339
 	zerolog.SetGlobalLevel(zerolog.InfoLevel)
 
-	output := zerolog.ConsoleWriter{
-		Out:        os.Stdout,
-		TimeFormat: time.RFC3339,
+	SetOutput(os.Stderr)
+}
+
+// SetOutput sends log records to w. Console colours are only used when w is a
+// terminal.
+func SetOutput(w io.Writer) {
+	output = w
+	rebuild()
+}
+
+// SetFormat changes how log records are written.
+func SetFormat(f Format) error {
+	switch f {
+	case FormatConsole, FormatJSON, FormatLogfmt:
+		format = f
+		rebuild()
+		return nil
+	}
+	return fmt.Errorf("unknown log format: %q", f)
+}
+
+func rebuild() {
+	var w io.Writer
+	switch format {
+	case FormatJSON:
+		w = output
+	case FormatLogfmt:
+		w = &logfmtWriter{out: output}
+	default:
+		w = zerolog.ConsoleWriter{
+			Out:        output,
+			TimeFormat: time.RFC3339,
+			NoColor:    !IsTerminal(output),
+		}
+	}
+
+	Logger = zerolog.New(w).With().Timestamp().Logger()
+}
+
+// SetLogFile appends log records to the file at path. The caller should close
+// the returned file when done.
+func SetLogFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening log file: %w", err)
 	}
+	SetOutput(file)
+	return file, nil
+}
 
-	Logger = log.Output(output)
+// IsTerminal reports whether v is a file connected to a terminal.
+func IsTerminal(v interface{}) bool {
+	file, ok := v.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(file.Fd()) || isatty.IsCygwinTerminal(file.Fd())
 }
 
 func SetLogLevel(level LogLevel) {
@@ -44,13 +122,27 @@ func SetLogLevel(level LogLevel) {
 	}
 }
 
+// UserOutput returns the writer UserMsg writes to, for results too large to
+// build as a single message.
+func UserOutput() io.Writer {
+	return userOut
+}
+
 func UserMsg(message string) {
-	_, err := os.Stdout.WriteString(message + "\n")
+	_, err := io.WriteString(userOut, message+"\n")
 	if err != nil {
 		Logger.Error().Msg(fmt.Sprintf("Error writing to stdout: %v", err))
 	}
 }
 
+// UserErr shows a message to the user on stderr, keeping it out of results.
+func UserErr(message string) {
+	_, err := io.WriteString(userErr, message+"\n")
+	if err != nil {
+		Logger.Error().Msg(fmt.Sprintf("Error writing to stderr: %v", err))
+	}
+}
+
 func Debug(message string) {
 	Logger.Debug().Msg(message)
 }
@@ -62,3 +154,15 @@ func Info(message string) {
 func Error(message string) {
 	Logger.Error().Msg(message)
 }
+
+func DebugFields(message string, fields Fields) {
+	Logger.Debug().Fields(map[string]interface{}(fields)).Msg(message)
+}
+
+func InfoFields(message string, fields Fields) {
+	Logger.Info().Fields(map[string]interface{}(fields)).Msg(message)
+}
+
+func ErrorFields(message string, fields Fields) {
+	Logger.Error().Fields(map[string]interface{}(fields)).Msg(message)
+}
@@ -1,6 +1,10 @@
 package logger
 
 import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/rs/zerolog"
@@ -50,3 +54,87 @@ func TestLogFunctions(t *testing.T) {
 	Error("test error message")
 	UserMsg("test user message")
 }
+
+func TestSetOutput(t *testing.T) {
+	defer SetOutput(os.Stderr)
+	SetLogLevel(LogLevelInfo)
+
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	Info("routed message")
+
+	assert.Contains(t, buf.String(), "routed message")
+	assert.NotContains(t, buf.String(), "\x1b[", "colours should be disabled for non-terminals")
+}
+
+func TestSetLogFile(t *testing.T) {
+	defer SetOutput(os.Stderr)
+	SetLogLevel(LogLevelInfo)
+
+	path := filepath.Join(t.TempDir(), "calculator.log")
+	file, err := SetLogFile(path)
+	assert.NoError(t, err)
+	Info("message in file")
+	assert.NoError(t, file.Close())
+
+	contents, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(contents), "message in file")
+
+	_, err = SetLogFile(filepath.Join(t.TempDir(), "missing", "calculator.log"))
+	assert.Error(t, err)
+}
+
+func TestUserOutputStreams(t *testing.T) {
+	defer func() {
+		userOut = os.Stdout
+		userErr = os.Stderr
+	}()
+
+	var out, errOut bytes.Buffer
+	userOut = &out
+	userErr = &errOut
+
+	UserMsg("result")
+	UserErr("problem")
+
+	assert.Equal(t, "result\n", out.String())
+	assert.Equal(t, "problem\n", errOut.String())
+}
+
+func TestIsTerminal(t *testing.T) {
+	assert.False(t, IsTerminal(&bytes.Buffer{}))
+
+	file, err := os.CreateTemp(t.TempDir(), "not-a-terminal")
+	assert.NoError(t, err)
+	defer file.Close()
+	assert.False(t, IsTerminal(file))
+}
+
+func TestSetFormat(t *testing.T) {
+	defer func() {
+		assert.NoError(t, SetFormat(FormatConsole))
+		SetOutput(os.Stderr)
+	}()
+	SetLogLevel(LogLevelDebug)
+	defer SetLogLevel(LogLevelInfo)
+
+	var buf bytes.Buffer
+	SetOutput(&buf)
+
+	assert.NoError(t, SetFormat(FormatJSON))
+	DebugFields("calculated", Fields{"term_count": 3})
+
+	var record map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "debug", record["level"])
+	assert.Equal(t, "calculated", record["message"])
+	assert.Equal(t, float64(3), record["term_count"])
+
+	buf.Reset()
+	assert.NoError(t, SetFormat(FormatLogfmt))
+	InfoFields("calculated", Fields{"term_count": 3, "formula": "1+2 = 3"})
+	assert.Regexp(t, `^time=\S+ level=info message=calculated formula="1\+2 = 3" term_count=3\n$`, buf.String())
+
+	assert.EqualError(t, SetFormat("xml"), "unknown log format: \"xml\"")
+}
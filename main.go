@@ -4,46 +4,358 @@ import (
 	"bufio"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"regexp"
+	"strings"
 
 	"challenge-calculator/calculate"
 	"challenge-calculator/logger"
 	"challenge-calculator/validate"
+
+	"github.com/shopspring/decimal"
 )
 
 var (
 	logLevel         = flag.String("log", "info", "Set the log level (debug, info, error)")
+	logFormat        = flag.String("log-format", "console", "Set the log format (console, json, logfmt)")
+	logFile          = flag.String("log-file", "", "Append log records to this file instead of stderr")
 	defaultDelimiter = flag.String("delimiter", "\n", "Set the default delimiter (default: newline)")
-	allowNegatives   = flag.Bool("allow-negatives", false, "Allow negative numbers in the input")
-	maxNumber        = flag.Int64("max-number", 1000, "Set the maximum number that can be included in calculations")
+	delimiterRegex   = flag.String("delimiter-regex", "", "Also split on matches of this regular expression")
+	allowNegatives   = flag.Bool("allow-negatives", false, "Allow negative numbers in the input; the same as -negatives=allow")
+	negatives        = flag.String("negatives", "", "Set how negative numbers are treated (error, allow, ignore, abs, error-total) (default: error)")
+	minNumber        = flag.String("min-number", "", "Set the minimum number that can be included in calculations (default: none)")
+	maxNumber        = flag.String("max-number", "1000", "Set the maximum number that can be included in calculations; empty for none")
+	minExclusive     = flag.Bool("min-exclusive", false, "Exclude the minimum itself from the range")
+	maxExclusive     = flag.Bool("max-exclusive", false, "Exclude the maximum itself from the range")
+	minAction        = flag.String("min-action", "exclude", "Set what happens to numbers below the minimum (exclude, clamp, error, warn)")
+	maxAction        = flag.String("max-action", "exclude", "Set what happens to numbers above the maximum (exclude, clamp, error, warn)")
+	operation        = flag.String("op", "add", "Set the operation to apply (add, subtract, multiply, divide)")
+	divPrecision     = flag.Int("division-precision", 16, "Set the number of decimal places kept when dividing")
+	mode             = flag.String("mode", "list", "Set the input mode (list, expression)")
+	onErrorFlag      = flag.String("on-error", "exit", "Set what happens when a line fails (exit, continue)")
+	strict           = flag.Bool("strict", false, "Reject input containing values that are not numbers instead of treating them as 0")
+	multiline        = flag.Bool("multiline", false, "Let a calculation span several lines, ending at a blank line or -terminator")
+	terminator       = flag.String("terminator", "", "End each multiline calculation at a line ending with this text instead of a blank line")
+	locale           = flag.String("locale", "", "Parse numbers written for this locale (en-US, de-DE, fr-FR, de-CH); its delimiter replaces \",\"")
+	formats          = flag.String("formats", "", "Also accept numbers in these comma-separated formats (currency, parentheses, trailing-minus, percent, scientific)")
+	currencies       = flag.String("currencies", "", "Only accept these comma-separated currency codes with -formats=currency")
+	money            = flag.Bool("money", false, "Add amounts of money, each with an optional currency, rounding the total to the currency's minor units")
+	currency         = flag.String("currency", "", "Set the currency of the total and of amounts written without one; implies -money")
+	rates            = flag.String("rates", "", "Read exchange rates for mixed currencies from this file of from,to,rate lines; implies -money")
+	rational         = flag.String("rational", "", "Calculate exactly with fractions such as 2/3, showing the total as a fraction, mixed number or decimal (fraction, mixed, decimal)")
+	precision        = flag.Int("precision", -1, "Round the terms and total shown to this many decimal places; -1 for none")
+	rounding         = flag.String("rounding", "half-up", "Set how -precision rounds (half-even, half-up, down, ceil, floor)")
+	fixed            = flag.Bool("fixed", false, "Show every decimal place set by -precision, as in 3.10")
+	roundTerms       = flag.Bool("round-terms", false, "Round each term to -precision before calculating, instead of only the total")
+	workers          = flag.Int("workers", 1, "Split very large inputs between this many goroutines")
+	unescape         = flag.String("unescape", "auto", "Replace typed \\n with a newline (auto, on, off); auto is on unless -multiline is set")
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "serve":
+			os.Exit(serve(os.Args[2:]))
+		case "batch":
+			os.Exit(batch(os.Args[2:]))
+		}
+	}
+
 	flag.Parse()
-	logger.SetLogLevel(logger.LogLevel(*logLevel))
+	closeLog, err := setupLogging(*logLevel, *logFormat, *logFile)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(2)
+	}
+	defer closeLog()
+
+	defaultOp, err := calculate.ParseOperation(*operation)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(2)
+	}
+	onError, err := parseErrorMode(*onErrorFlag)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(2)
+	}
+	if *mode != "list" && *mode != "expression" {
+		logger.Error(fmt.Sprintf("unknown mode: %q", *mode))
+		os.Exit(2)
+	}
+	if *unescape != "auto" && *unescape != "on" && *unescape != "off" {
+		logger.Error(fmt.Sprintf("unknown -unescape setting: %q", *unescape))
+		os.Exit(2)
+	}
+
+	calculator, err := newCalculator()
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(2)
+	}
+	if err := checkDelimiters(calculator); err != nil {
+		logger.Error(err.Error())
+		os.Exit(2)
+	}
 
-	validate.SetDefaultDelimiter(*defaultDelimiter)
-	validate.SetAllowNegatives(*allowNegatives)
-	calculate.SetMaxValidNumber(*maxNumber)
+	s := &session{
+		calculator: calculator,
+		defaultOp:  defaultOp,
+		onError:    onError,
+		multiline:  *multiline,
+		terminator: *terminator,
+	}
 
-	scanner := bufio.NewScanner(os.Stdin)
-	logger.UserMsg("Please enter the numbers to be calculated, separated by a comma:")
+	// Prompts are only useful to someone typing; piped input gets results only.
+	if logger.IsTerminal(os.Stdin) {
+		logger.UserMsg("Please enter the numbers to be calculated, separated by a comma:")
+		if *multiline {
+			logger.UserMsg(fmt.Sprintf("End each calculation with %s.", endOfCalculation()))
+		}
+	}
+	if err := s.run(os.Stdin); err != nil {
+		logger.Error(fmt.Sprintf("Error reading input: %v", err))
+		os.Exit(1)
+	}
 
-	for scanner.Scan() {
-		input := scanner.Text()
-		unescapedInput := validate.UnescapeNewline(input)
+	if s.onError == onErrorContinue {
+		logger.UserErr(s.summary())
+	}
+	if s.failures > 0 {
+		os.Exit(1)
+	}
+}
 
-		result, err := calculate.Add(unescapedInput)
+// setupLogging applies the logging flags shared by every command. The
+// returned function closes the log file, if one was opened.
+func setupLogging(level, format, file string) (func(), error) {
+	logger.SetLogLevel(logger.LogLevel(level))
+	if err := logger.SetFormat(logger.Format(format)); err != nil {
+		return nil, err
+	}
+	if file == "" {
+		return func() {}, nil
+	}
+
+	logFile, err := logger.SetLogFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return func() { logFile.Close() }, nil
+}
+
+// checkDelimiters prints the warnings about the delimiters of calculator, or
+// returns why they cannot be used.
+func checkDelimiters(calculator *calculate.Calculator) error {
+	warnings, err := calculator.CheckDelimiters()
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		logger.UserErr("Warning: " + warning)
+	}
+	return nil
+}
+
+// newCalculator builds a Calculator from the command line flags.
+func newCalculator() (*calculate.Calculator, error) {
+	if *divPrecision < 0 || *divPrecision > calculate.MaxPrecisionPlaces {
+		return nil, fmt.Errorf("invalid -division-precision: %d, must be from 0 to %d", *divPrecision, calculate.MaxPrecisionPlaces)
+	}
+	opts := []calculate.Option{
+		calculate.WithDefaultDelimiter(*defaultDelimiter),
+		calculate.WithDivisionPrecision(int32(*divPrecision)),
+		calculate.WithStrict(*strict),
+		calculate.WithWorkers(*workers),
+	}
+	policy, err := negativesPolicy()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, calculate.WithNegatives(policy))
+	valueRange, err := rangeFlags()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, calculate.WithRange(valueRange))
+	if *locale != "" {
+		l, err := validate.LookupLocale(*locale)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -locale: %w", err)
+		}
+		opts = append(opts, calculate.WithLocale(l))
+	}
+	if *formats != "" || *currencies != "" {
+		f, err := validate.ParseFormats(splitList(*formats), splitList(*currencies)...)
 		if err != nil {
-			logger.UserMsg(fmt.Sprintf("Error calculating result: %v", err))
-			os.Exit(1)
+			return nil, fmt.Errorf("invalid -formats: %w", err)
+		}
+		opts = append(opts, calculate.WithFormats(f...))
+	}
+	if *money || *currency != "" || *rates != "" {
+		m := calculate.Money{Currency: strings.ToUpper(*currency)}
+		if _, ok := validate.CurrencyMinorUnits(m.Currency); m.Currency != "" && !ok {
+			return nil, fmt.Errorf("invalid -currency: unknown currency code %q", *currency)
+		}
+		if *rates != "" {
+			if m.Rates, err = calculate.LoadRates(*rates); err != nil {
+				return nil, fmt.Errorf("invalid -rates: %w", err)
+			}
+		}
+		opts = append(opts, calculate.WithMoney(m))
+	}
+	if *rational != "" {
+		format, err := calculate.ParseRationalFormat(*rational)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -rational: %w", err)
+		}
+		if *money || *currency != "" || *rates != "" {
+			return nil, fmt.Errorf("invalid -rational: amounts of money cannot be fractions")
 		}
+		opts = append(opts, calculate.WithRational(format))
+	}
+	if *precision != -1 {
+		if *precision < 0 || *precision > calculate.MaxPrecisionPlaces {
+			return nil, fmt.Errorf("invalid -precision: %d, must be from 0 to %d", *precision, calculate.MaxPrecisionPlaces)
+		}
+		mode, err := calculate.ParseRoundingMode(*rounding)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -rounding: %w", err)
+		}
+		if *money || *currency != "" || *rates != "" {
+			return nil, fmt.Errorf("invalid -precision: amounts of money are rounded to their currency")
+		}
+		opts = append(opts, calculate.WithPrecision(calculate.Precision{Places: int32(*precision), Rounding: mode, Fixed: *fixed, RoundTerms: *roundTerms}))
+	}
+	if *workers < 1 {
+		return nil, fmt.Errorf("invalid -workers: %d, must be at least 1", *workers)
+	}
+	if *delimiterRegex != "" {
+		pattern, err := regexp.Compile(*delimiterRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -delimiter-regex: %v", err)
+		}
+		opts = append(opts, calculate.WithDelimiterPattern(pattern))
+	}
+	return calculate.New(opts...), nil
+}
 
-		logger.UserMsg(result)
+// negativesPolicy reads -negatives, which takes precedence over
+// -allow-negatives.
+func negativesPolicy() (validate.NegativesPolicy, error) {
+	if *negatives == "" {
+		if *allowNegatives {
+			return validate.NegativesAllow, nil
+		}
+		return validate.NegativesError, nil
 	}
+	policy, err := validate.ParseNegativesPolicy(*negatives)
+	if err != nil {
+		return "", fmt.Errorf("invalid -negatives: %v", err)
+	}
+	return policy, nil
+}
 
-	if err := scanner.Err(); err != nil {
-		logger.Error(fmt.Sprintf("Error reading input: %v", err))
-		os.Exit(1)
+// rangeFlags builds the range of numbers included in calculations from the
+// command line flags.
+func rangeFlags() (calculate.Range, error) {
+	var r calculate.Range
+	var err error
+	if r.Min, err = parseBound("min", *minNumber, *minExclusive, *minAction); err != nil {
+		return r, err
+	}
+	if r.Max, err = parseBound("max", *maxNumber, *maxExclusive, *maxAction); err != nil {
+		return r, err
+	}
+	return r, r.Validate()
+}
+
+// parseBound reads one bound of the range. An empty value means no bound.
+func parseBound(name, value string, exclusive bool, action string) (*calculate.Bound, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	number, err := decimal.NewFromString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("invalid -%s-number: %q", name, value)
+	}
+	boundAction, err := calculate.ParseBoundAction(action)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -%s-action: %v", name, err)
+	}
+	return &calculate.Bound{Value: number, Exclusive: exclusive, Action: boundAction}, nil
+}
+
+// splitList splits a comma-separated flag value, ignoring empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func calculateLine(calculator *calculate.Calculator, defaultOp calculate.Operation, line string) (*calculate.Result, error) {
+	if *mode == "expression" {
+		return calculator.Evaluate(line)
+	}
+
+	op, input, found := calculate.SplitOperationPrefix(line)
+	if !found {
+		op = defaultOp
+	}
+	if shouldUnescape() {
+		input = validate.UnescapeNewline(input)
+	}
+	return calculator.Calculate(op, input)
+}
+
+// calculateStream is calculateLine for a line too long to hold in memory.
+// The formula is written to formula as the line is read.
+func calculateStream(calculator *calculate.Calculator, defaultOp calculate.Operation, r io.Reader, formula io.Writer) (*calculate.StreamResult, error) {
+	reader := bufio.NewReader(r)
+
+	// Operation names are short, so the prefix fits well within the peek.
+	op := defaultOp
+	start, _ := reader.Peek(16)
+	if prefixOp, input, found := calculate.SplitOperationPrefix(string(start)); found {
+		op = prefixOp
+		reader.Discard(len(start) - len(input))
+	}
+
+	var input io.Reader = reader
+	if shouldUnescape() {
+		input = validate.NewUnescapeReader(reader)
+	}
+	return calculator.CalculateStream(op, input, formula)
+}
+
+// canStream reports whether long lines can be calculated as they are read.
+// Expressions and regular-expression delimiters need the whole line.
+func canStream() bool {
+	return *mode != "expression" && *delimiterRegex == ""
+}
+
+// shouldUnescape reports whether typed "\n" sequences become newlines. By
+// default they do for single-line input, where a newline cannot be typed,
+// and do not in multiline mode, where input is taken as written.
+func shouldUnescape() bool {
+	switch *unescape {
+	case "on":
+		return true
+	case "off":
+		return false
+	}
+	return !*multiline
+}
+
+// endOfCalculation describes how a multiline calculation is ended.
+func endOfCalculation() string {
+	if *terminator != "" {
+		return fmt.Sprintf("a line ending in %q", *terminator)
 	}
+	return "a blank line"
 }
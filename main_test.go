@@ -1,6 +1,11 @@
 package main
 
 import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"challenge-calculator/calculate"
@@ -70,9 +75,130 @@ func TestMainFunctionality(t *testing.T) {
 					assert.Error(t, err)
 				} else {
 					assert.NoError(t, err)
-					assert.Equal(t, test.expected, sum)
+					assert.Equal(t, test.expected, sum.String())
 				}
 			})
 		}
 	})
 }
+
+// resetFlags sets the flags shared with subcommands back to their defaults.
+func resetFlags() {
+	for _, name := range sharedFlags {
+		f := flag.Lookup(name)
+		f.Value.Set(f.DefValue)
+	}
+}
+
+func TestFlags(t *testing.T) {
+	defer resetFlags()
+
+	ratesFile := filepath.Join(t.TempDir(), "rates.csv")
+	assert.NoError(t, os.WriteFile(ratesFile, []byte("EUR,USD,1.09\n"), 0o644))
+	missing := filepath.Join(t.TempDir(), "missing.csv")
+
+	tests := []struct {
+		name             string
+		set              func()
+		input            string
+		expected         string
+		expectedWarnings []string
+		expectedErr      string
+	}{
+		{name: "delimiter regex", set: func() { *delimiterRegex = `[;|\s]+` }, input: "1; 2 | 3;|4", expected: "1+2+3+4 = 10"},
+		{name: "invalid delimiter regex", set: func() { *delimiterRegex = "[;" }, expectedErr: "invalid -delimiter-regex: error parsing regexp: missing closing ]: `[;`"},
+
+		{name: "default maximum", set: func() {}, input: "1,1000,1001", expected: "1+1000+0 = 1001"},
+		{name: "decimal maximum", set: func() { *maxNumber = "999.99" }, input: "999.99,1000", expected: "999.99+0 = 999.99"},
+		{name: "no maximum", set: func() { *maxNumber = "" }, input: "1,5000", expected: "1+5000 = 5001"},
+		{name: "exclusive maximum", set: func() { *maxNumber, *maxExclusive = "10", true }, input: "9,10", expected: "9+0 = 9"},
+		{name: "clamped minimum", set: func() { *minNumber, *minAction = "2", "clamp" }, input: "1,3", expected: "2+3 = 5"},
+		{name: "maximum error", set: func() { *maxAction = "error" }, input: "1,2000", expectedErr: "number out of range: term 2 (2000) is greater than maximum 1000"},
+		{name: "invalid number", set: func() { *maxNumber = "lots" }, expectedErr: `invalid -max-number: "lots"`},
+		{name: "invalid action", set: func() { *minNumber, *minAction = "0", "skip" }, expectedErr: `invalid -min-action: unknown bound action: "skip"`},
+		{name: "empty range", set: func() { *minNumber, *maxNumber = "5", "1" }, expectedErr: "invalid range: no number is within minimum 5 and maximum 1"},
+
+		{name: "negatives by default", set: func() {}, input: "1,-3,2", expectedErr: "invalid input: negative numbers found: -3"},
+		{name: "allow-negatives", set: func() { *allowNegatives = true }, input: "1,-3,2", expected: "1+-3+2 = 0"},
+		{name: "ignore negatives", set: func() { *negatives = "ignore" }, input: "1,-3,2", expected: "1+[-3]+2 = 3"},
+		{name: "abs negatives", set: func() { *negatives = "abs" }, input: "1,-3,2", expected: "1+|-3|+2 = 6"},
+		{name: "negatives wins over allow-negatives", set: func() { *negatives, *allowNegatives = "error", true }, input: "1,-3,2", expectedErr: "invalid input: negative numbers found: -3"},
+		{name: "unknown negatives policy", set: func() { *negatives = "sometimes" }, expectedErr: `invalid -negatives: unknown negatives policy: "sometimes"`},
+
+		{name: "de-DE locale", set: func() { *locale = "de-DE" }, input: "1,5;2.000\n0,25", expected: "1.5+0+0.25 = 1.75"},
+		{name: "en-US locale", set: func() { *locale = "en-US" }, input: `"1,000",2.5`, expected: "1000+2.5 = 1002.5"},
+		{name: "unknown locale", set: func() { *locale = "xx-XX" }, expectedErr: `invalid -locale: unknown locale: "xx-XX"`},
+
+		{name: "formats", set: func() { *formats, *allowNegatives = "currency, parentheses,percent", true }, input: `$12.50\n(2.50)\n10%`, expected: "12.5+-2.5+0.1 = 10.1"},
+		{name: "currencies", set: func() { *formats, *currencies = "currency", "EUR" }, input: "€5,$5", expected: "5+0 = 5", expectedWarnings: []string{`invalid token "$5" at index 1 (offset 5): currency USD is not accepted, treated as 0`}},
+		{name: "unknown format", set: func() { *formats = "roman" }, expectedErr: `invalid -formats: unknown number format: "roman"`},
+
+		{name: "money", set: func() { *money = true }, input: "$12.50,3 USD", expected: "12.5+3 = 15.50 USD"},
+		{name: "exchange rates", set: func() { *currency, *rates = "usd", ratesFile }, input: "12.50,3 EUR", expected: "12.5+3.27 (3 EUR) = 15.77 USD"},
+		{name: "unknown currency", set: func() { *currency = "XYZ" }, expectedErr: `invalid -currency: unknown currency code "XYZ"`},
+		{name: "missing rates", set: func() { *rates = missing }, expectedErr: "invalid -rates: open " + missing + ": no such file or directory"},
+
+		{name: "rational", set: func() { *rational = "mixed" }, input: "1/3,2/3,5/6", expected: "1/3+2/3+5/6 = 1 5/6"},
+		{name: "unknown rational format", set: func() { *rational = "thirds" }, expectedErr: `invalid -rational: unknown rational format: "thirds"`},
+		{name: "rational money", set: func() { *rational, *money = "fraction", true }, expectedErr: "invalid -rational: amounts of money cannot be fractions"},
+
+		{name: "fixed precision", set: func() { *precision, *fixed = 2, true }, input: "1.1,2", expected: "1.10+2.00 = 3.10"},
+		{name: "rounded terms", set: func() { *precision, *roundTerms = 1, true }, input: "1.14,1.14", expected: "1.1+1.1 = 2.2"},
+		{name: "unknown rounding mode", set: func() { *precision, *rounding = 1, "sideways" }, expectedErr: `invalid -rounding: unknown rounding mode: "sideways"`},
+		{name: "negative precision", set: func() { *precision = -2 }, expectedErr: "invalid -precision: -2, must be from 0 to 100"},
+		{name: "too much precision", set: func() { *precision = 100000000 }, expectedErr: "invalid -precision: 100000000, must be from 0 to 100"},
+		{name: "precision money", set: func() { *precision, *money = 2, true }, expectedErr: "invalid -precision: amounts of money are rounded to their currency"},
+
+		{name: "workers", set: func() { *workers = 4 }, input: "1,2,3", expected: "1+2+3 = 6"},
+		{name: "division precision", set: func() { *divPrecision = 2 }, input: "div:2,3", expected: "2/3 = 0.67"},
+		{name: "negative division precision", set: func() { *divPrecision = -1 }, expectedErr: "invalid -division-precision: -1, must be from 0 to 100"},
+		{name: "too much division precision", set: func() { *divPrecision = 100000000 }, expectedErr: "invalid -division-precision: 100000000, must be from 0 to 100"},
+
+		{name: "no workers", set: func() { *workers = 0 }, expectedErr: "invalid -workers: 0, must be at least 1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetFlags()
+			test.set()
+			calculator, err := newCalculator()
+			if err == nil {
+				var result *calculate.Result
+				if result, err = calculateLine(calculator, "add", test.input); err == nil {
+					assert.Equal(t, test.expected, result.String())
+					assert.Equal(t, test.expectedWarnings, result.Warnings)
+				}
+			}
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCalculateStream(t *testing.T) {
+	*mode = "list"
+	calculator, err := newCalculator()
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "default operation", input: "1,2,3", expected: "1+2+3 = 6"},
+		{name: "operation prefix", input: "mul:2,3,4", expected: "2*3*4 = 24"},
+		{name: "escaped newline", input: `//;\n1;2`, expected: "1+2 = 3"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var formula bytes.Buffer
+			_, err := calculateStream(calculator, "add", strings.NewReader(test.input), &formula)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, formula.String())
+		})
+	}
+}
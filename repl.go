@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"challenge-calculator/calculate"
+	"challenge-calculator/logger"
+)
+
+type errorMode string
+
+const (
+	onErrorExit     errorMode = "exit"
+	onErrorContinue errorMode = "continue"
+)
+
+func parseErrorMode(value string) (errorMode, error) {
+	switch errorMode(value) {
+	case onErrorExit, onErrorContinue:
+		return errorMode(value), nil
+	}
+	return "", fmt.Errorf("unknown -on-error mode: %q", value)
+}
+
+// session reads calculations and keeps count of the outcome. Each line is a
+// calculation unless multiline is set, in which case calculations end at a
+// blank line or at terminator.
+type session struct {
+	calculator *calculate.Calculator
+	defaultOp  calculate.Operation
+	onError    errorMode
+	multiline  bool
+	terminator string
+
+	successes int
+	failures  int
+}
+
+// run processes every calculation in r. In exit mode it stops at the first
+// one that fails. The returned error is only set when reading r fails.
+func (s *session) run(r io.Reader) error {
+	records := newRecordReader(r, s.multiline, s.terminator, canStream())
+	for {
+		rec, ok := records.Next()
+		if !ok {
+			break
+		}
+
+		var err error
+		if rec.stream != nil {
+			err = s.calculateStream(rec)
+		} else {
+			var result *calculate.Result
+			result, err = calculateLine(s.calculator, s.defaultOp, rec.text)
+			if err == nil {
+				logger.UserMsg(calculate.FormulaFormatter{}.Format(result))
+				for _, warning := range result.Warnings {
+					logger.UserErr("Warning: " + warning)
+				}
+			}
+		}
+		if err != nil {
+			s.failures++
+			if s.onError == onErrorContinue {
+				logger.UserErr(fmt.Sprintf("Error calculating result on line %d: %v", rec.line, err))
+			} else {
+				logger.UserErr(fmt.Sprintf("Error calculating result: %v", err))
+			}
+			// A streamed record has no text to highlight; the error gives
+			// the offset instead.
+			if rec.stream == nil {
+				if highlighted := highlightError(rec.text, err); highlighted != "" {
+					logger.UserErr(highlighted)
+				}
+			}
+			if s.onError == onErrorExit {
+				return nil
+			}
+			continue
+		}
+		s.successes++
+	}
+
+	return records.Err()
+}
+
+// calculateStream writes the formula for a streamed record once the whole
+// record has been calculated.
+func (s *session) calculateStream(rec record) error {
+	out := logger.UserOutput()
+	result, err := calculateStream(s.calculator, s.defaultOp, rec.stream, out)
+	if err != nil {
+		return err
+	}
+	io.WriteString(out, "\n")
+	for _, warning := range result.Warnings {
+		logger.UserErr("Warning: " + warning)
+	}
+	return nil
+}
+
+func (s *session) summary() string {
+	unit := "lines"
+	if s.multiline {
+		unit = "calculations"
+	}
+	return fmt.Sprintf("Processed %d %s: %d succeeded, %d failed", s.successes+s.failures, unit, s.successes, s.failures)
+}
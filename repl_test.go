@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseErrorMode(t *testing.T) {
+	mode, err := parseErrorMode("continue")
+	assert.NoError(t, err)
+	assert.Equal(t, onErrorContinue, mode)
+
+	mode, err = parseErrorMode("exit")
+	assert.NoError(t, err)
+	assert.Equal(t, onErrorExit, mode)
+
+	_, err = parseErrorMode("ignore")
+	assert.EqualError(t, err, "unknown -on-error mode: \"ignore\"")
+}
+
+func TestSessionRun(t *testing.T) {
+	*mode = "list"
+	input := "1,2\n-1\n3,4\n//[*\\n1*2\n5\n"
+
+	tests := []struct {
+		name              string
+		onError           errorMode
+		expectedSuccesses int
+		expectedFailures  int
+		expectedSummary   string
+	}{
+		{
+			name:              "exit stops at the first failure",
+			onError:           onErrorExit,
+			expectedSuccesses: 1,
+			expectedFailures:  1,
+			expectedSummary:   "Processed 2 lines: 1 succeeded, 1 failed",
+		},
+		{
+			name:              "continue reads every line",
+			onError:           onErrorContinue,
+			expectedSuccesses: 3,
+			expectedFailures:  2,
+			expectedSummary:   "Processed 5 lines: 3 succeeded, 2 failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calculator, err := newCalculator()
+			assert.NoError(t, err)
+			s := &session{calculator: calculator, defaultOp: "add", onError: test.onError}
+			assert.NoError(t, s.run(strings.NewReader(input)))
+			assert.Equal(t, test.expectedSuccesses, s.successes)
+			assert.Equal(t, test.expectedFailures, s.failures)
+			assert.Equal(t, test.expectedSummary, s.summary())
+		})
+	}
+}
+
+func TestSessionRunMultiline(t *testing.T) {
+	*mode = "list"
+	*multiline = true
+	defer func() { *multiline = false }()
+
+	input := "//;\n1;2;3\n\n4\n5\n\n1,-2\n\n1,2\\n3\n"
+	calculator, err := newCalculator()
+	assert.NoError(t, err)
+	s := &session{calculator: calculator, defaultOp: "add", onError: onErrorContinue, multiline: true}
+
+	assert.NoError(t, s.run(strings.NewReader(input)))
+	assert.Equal(t, 3, s.successes)
+	assert.Equal(t, 1, s.failures)
+	assert.Equal(t, "Processed 4 calculations: 3 succeeded, 1 failed", s.summary())
+}
+
+func TestShouldUnescape(t *testing.T) {
+	defer func() { *multiline, *unescape = false, "auto" }()
+
+	tests := []struct {
+		multiline bool
+		unescape  string
+		expected  bool
+	}{
+		{multiline: false, unescape: "auto", expected: true},
+		{multiline: true, unescape: "auto", expected: false},
+		{multiline: true, unescape: "on", expected: true},
+		{multiline: false, unescape: "off", expected: false},
+	}
+
+	for _, test := range tests {
+		*multiline, *unescape = test.multiline, test.unescape
+		assert.Equal(t, test.expected, shouldUnescape(), "multiline=%v unescape=%s", test.multiline, test.unescape)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"challenge-calculator/logger"
+	"challenge-calculator/server"
+)
+
+// serve runs the HTTP API until interrupted and returns the exit status.
+func serve(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", ":8080", "Set the address to listen on")
+	level := fs.String("log", "info", "Set the log level (debug, info, error)")
+	format := fs.String("log-format", "console", "Set the log format (console, json, logfmt)")
+	file := fs.String("log-file", "", "Append log records to this file instead of stderr")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	closeLog, err := setupLogging(*level, *format, *file)
+	if err != nil {
+		logger.Error(err.Error())
+		return 2
+	}
+	defer closeLog()
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           server.New(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() {
+		logger.InfoFields("Server listening", logger.Fields{"addr": *addr})
+		errs <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		logger.ErrorFields("Server stopped", logger.Fields{"error": err.Error()})
+		return 1
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.ErrorFields("Error shutting down server", logger.Fields{"error": err.Error()})
+		return 1
+	}
+	logger.Info("Server stopped")
+	return 0
+}
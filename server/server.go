@@ -0,0 +1,311 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"challenge-calculator/calculate"
+	"challenge-calculator/logger"
+	"challenge-calculator/validate"
+
+	"github.com/shopspring/decimal"
+)
+
+// maxBodyBytes limits the size of a request body.
+const maxBodyBytes = 1 << 20
+
+// maxExponent limits the exponent of numbers in a request, so that a few
+// bytes such as "1e-50000000" cannot take minutes and megabytes to add up.
+const maxExponent = 100
+
+// Error codes returned in the "code" field of error responses.
+const (
+	CodeInvalidRequest     = "invalid_request"
+	CodeBodyTooLarge       = "body_too_large"
+	CodeMethodNotAllowed   = "method_not_allowed"
+	CodeNegativeNumbers    = "negative_numbers"
+	CodeDelimiterSyntax    = "delimiter_syntax"
+	CodeInvalidToken       = "invalid_token"
+	CodeAmbiguousDelimiter = "ambiguous_delimiter"
+	CodeOutOfRange         = "out_of_range"
+	CodeNegativeTotal      = "negative_total"
+	CodeMixedCurrencies    = "mixed_currencies"
+	CodeDivideByZero       = "divide_by_zero"
+	CodeInternal           = "internal_error"
+)
+
+// Request is the JSON body accepted by the calculation endpoints. Settings
+// left out keep the server defaults. The exclusive and action settings of a
+// bound only apply along with its number.
+type Request struct {
+	Input          string                   `json:"input"`
+	AllowNegatives *bool                    `json:"allowNegatives,omitempty"`
+	Negatives      string                   `json:"negatives,omitempty"`
+	MinNumber      *decimal.Decimal         `json:"minNumber,omitempty"`
+	MaxNumber      *decimal.Decimal         `json:"maxNumber,omitempty"`
+	MinExclusive   bool                     `json:"minExclusive,omitempty"`
+	MaxExclusive   bool                     `json:"maxExclusive,omitempty"`
+	MinAction      calculate.BoundAction    `json:"minAction,omitempty"`
+	MaxAction      calculate.BoundAction    `json:"maxAction,omitempty"`
+	Delimiters     []string                 `json:"delimiters,omitempty"`
+	Locale         string                   `json:"locale,omitempty"`
+	Formats        []string                 `json:"formats,omitempty"`
+	Currencies     []string                 `json:"currencies,omitempty"`
+	Money          bool                     `json:"money,omitempty"`
+	Currency       string                   `json:"currency,omitempty"`
+	Rational       calculate.RationalFormat `json:"rational,omitempty"`
+	Precision      *int32                   `json:"precision,omitempty"`
+	Rounding       calculate.RoundingMode   `json:"rounding,omitempty"`
+	Fixed          bool                     `json:"fixed,omitempty"`
+	RoundTerms     bool                     `json:"roundTerms,omitempty"`
+	Strict         *bool                    `json:"strict,omitempty"`
+}
+
+// Response is a successful calculation: the structured result plus the
+// formula the CLI would print.
+type Response struct {
+	*calculate.Result
+	Formula string `json:"formula"`
+}
+
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Positions []int  `json:"positions,omitempty"`
+}
+
+// Server serves the calculator over HTTP. Every request gets its own
+// Calculator, so settings never leak between requests.
+type Server struct {
+	mux      *http.ServeMux
+	baseOpts []calculate.Option
+}
+
+// New returns a Server whose calculators start from baseOpts, with the
+// per-request settings applied on top.
+func New(baseOpts ...calculate.Option) *Server {
+	s := &Server{
+		mux:      http.NewServeMux(),
+		baseOpts: baseOpts,
+	}
+	s.mux.HandleFunc("/v1/add", s.handleAdd)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	s.mux.ServeHTTP(recorder, r)
+
+	logger.InfoFields("Request handled", logger.Fields{
+		"method":      r.Method,
+		"path":        r.URL.Path,
+		"status":      recorder.status,
+		"duration_ms": float64(time.Since(start).Microseconds()) / 1000,
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (s *Server) handleAdd(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, ErrorBody{Code: CodeMethodNotAllowed, Message: "only POST is supported"})
+		return
+	}
+
+	req, reqErr := readRequest(w, r)
+	if reqErr != nil {
+		writeError(w, reqErr.status, reqErr.body)
+		return
+	}
+
+	opts, err := req.options()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrorBody{Code: CodeInvalidRequest, Message: err.Error()})
+		return
+	}
+	opts = append(opts, calculate.WithMaxExponent(maxExponent))
+	calculator := calculate.New(append(append([]calculate.Option{}, s.baseOpts...), opts...)...)
+	result, err := calculator.Add(req.Input)
+	if err != nil {
+		status, body := classifyError(err)
+		writeError(w, status, body)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Result: result, Formula: result.String()})
+}
+
+type requestError struct {
+	status int
+	body   ErrorBody
+}
+
+// readRequest accepts either a JSON Request or the raw input as text.
+func readRequest(w http.ResponseWriter, r *http.Request) (*Request, *requestError) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, &requestError{
+				status: http.StatusRequestEntityTooLarge,
+				body:   ErrorBody{Code: CodeBodyTooLarge, Message: fmt.Sprintf("request body exceeds %d bytes", maxBodyBytes)},
+			}
+		}
+		return nil, &requestError{
+			status: http.StatusBadRequest,
+			body:   ErrorBody{Code: CodeInvalidRequest, Message: fmt.Sprintf("reading request body: %v", err)},
+		}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		return &Request{Input: string(body)}, nil
+	}
+
+	var req Request
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, &requestError{
+			status: http.StatusBadRequest,
+			body:   ErrorBody{Code: CodeInvalidRequest, Message: fmt.Sprintf("invalid JSON body: %v", err)},
+		}
+	}
+	return &req, nil
+}
+
+// options returns the settings of req. negatives takes precedence over
+// allowNegatives.
+func (req *Request) options() ([]calculate.Option, error) {
+	var opts []calculate.Option
+	if req.AllowNegatives != nil {
+		opts = append(opts, calculate.WithAllowNegatives(*req.AllowNegatives))
+	}
+	if req.Negatives != "" {
+		policy, err := validate.ParseNegativesPolicy(req.Negatives)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, calculate.WithNegatives(policy))
+	}
+	if outOfScale(req.MinNumber) || outOfScale(req.MaxNumber) {
+		return nil, fmt.Errorf("minNumber and maxNumber must have an exponent within ±%d", maxExponent)
+	}
+	if req.MinNumber != nil {
+		opts = append(opts, calculate.WithMin(&calculate.Bound{Value: *req.MinNumber, Exclusive: req.MinExclusive, Action: req.MinAction}))
+	}
+	if req.MaxNumber != nil {
+		opts = append(opts, calculate.WithMax(&calculate.Bound{Value: *req.MaxNumber, Exclusive: req.MaxExclusive, Action: req.MaxAction}))
+	}
+	if len(req.Delimiters) > 0 {
+		opts = append(opts, calculate.WithDelimiters(req.Delimiters...))
+	}
+	if req.Locale != "" {
+		locale, err := validate.LookupLocale(req.Locale)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, calculate.WithLocale(locale))
+	}
+	if len(req.Formats) > 0 || len(req.Currencies) > 0 {
+		formats, err := validate.ParseFormats(req.Formats, req.Currencies...)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, calculate.WithFormats(formats...))
+	}
+	if req.Money || req.Currency != "" {
+		opts = append(opts, calculate.WithMoney(calculate.Money{Currency: strings.ToUpper(req.Currency)}))
+	}
+	if req.Rational != "" {
+		opts = append(opts, calculate.WithRational(req.Rational))
+	}
+	if req.Precision != nil {
+		opts = append(opts, calculate.WithPrecision(calculate.Precision{Places: *req.Precision, Rounding: req.Rounding, Fixed: req.Fixed, RoundTerms: req.RoundTerms}))
+	}
+	if req.Strict != nil {
+		opts = append(opts, calculate.WithStrict(*req.Strict))
+	}
+	return opts, nil
+}
+
+// outOfScale reports whether bound has an exponent beyond maxExponent.
+func outOfScale(bound *decimal.Decimal) bool {
+	return bound != nil && (bound.Exponent() > maxExponent || bound.Exponent() < -maxExponent)
+}
+
+// classifyError maps a calculation error to a status and error code.
+func classifyError(err error) (int, ErrorBody) {
+	body := ErrorBody{Message: err.Error()}
+
+	var negativeErr *validate.NegativeNumbersError
+	var delimiterErr *validate.DelimiterSyntaxError
+	var tokensErr *validate.InvalidTokensError
+	var ambiguousErr *validate.AmbiguousDelimiterError
+	var rangeErr *calculate.RangeError
+	var currencyErr *calculate.CurrencyError
+
+	switch {
+	case errors.As(err, &negativeErr):
+		body.Code = CodeNegativeNumbers
+		body.Positions = negativeErr.Positions
+	case errors.As(err, &delimiterErr):
+		body.Code = CodeDelimiterSyntax
+		body.Positions = []int{delimiterErr.Offset}
+	case errors.As(err, &tokensErr):
+		body.Code = CodeInvalidToken
+		body.Positions = tokensErr.Offsets()
+	case errors.As(err, &ambiguousErr):
+		body.Code = CodeAmbiguousDelimiter
+		if ambiguousErr.Offset >= 0 {
+			body.Positions = []int{ambiguousErr.Offset}
+		}
+	case errors.As(err, &rangeErr):
+		body.Code = CodeOutOfRange
+		body.Positions = []int{rangeErr.Offset}
+	case errors.As(err, &currencyErr):
+		body.Code = CodeMixedCurrencies
+		body.Positions = []int{currencyErr.Offset}
+	case errors.Is(err, calculate.ErrInvalidRange), errors.Is(err, calculate.ErrInvalidMoney), errors.Is(err, calculate.ErrInvalidRational), errors.Is(err, calculate.ErrInvalidPrecision):
+		body.Code = CodeInvalidRequest
+		return http.StatusBadRequest, body
+	case errors.Is(err, calculate.ErrNegativeTotal):
+		body.Code = CodeNegativeTotal
+	case errors.Is(err, calculate.ErrDivideByZero):
+		body.Code = CodeDivideByZero
+	default:
+		logger.ErrorFields("Unexpected calculation error", logger.Fields{"error": err.Error()})
+		return http.StatusInternalServerError, ErrorBody{Code: CodeInternal, Message: "internal error"}
+	}
+	return http.StatusUnprocessableEntity, body
+}
+
+func writeError(w http.ResponseWriter, status int, body ErrorBody) {
+	writeJSON(w, status, ErrorResponse{Error: body})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.ErrorFields("Error writing response", logger.Fields{"error": err.Error()})
+	}
+}
@@ -0,0 +1,330 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"challenge-calculator/calculate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func post(t *testing.T, handler http.Handler, contentType, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/v1/add", strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAddSuccess(t *testing.T) {
+	tests := []struct {
+		name            string
+		contentType     string
+		body            string
+		expectedFormula string
+		expectedTotal   string
+		expectedDropped int
+	}{
+		{
+			name:            "raw text",
+			contentType:     "text/plain",
+			body:            "1,2\n1001",
+			expectedFormula: "1+2+0 = 3",
+			expectedTotal:   "3",
+			expectedDropped: 1,
+		},
+		{
+			name:            "raw text without content type",
+			body:            "//;\n1;2",
+			expectedFormula: "1+2 = 3",
+			expectedTotal:   "3",
+		},
+		{
+			name:            "json with per-request settings",
+			contentType:     "application/json; charset=utf-8",
+			body:            `{"input": "1|-2|600", "allowNegatives": true, "maxNumber": 500, "delimiters": ["|"]}`,
+			expectedFormula: "1+-2+0 = -1",
+			expectedTotal:   "-1",
+			expectedDropped: 1,
+		},
+		{
+			name:            "json with range settings",
+			contentType:     "application/json",
+			body:            `{"input": "0.5,5,999.995", "minNumber": 1, "minAction": "clamp", "maxNumber": "999.99"}`,
+			expectedFormula: "1+5+0 = 6",
+			expectedTotal:   "6",
+			expectedDropped: 2,
+		},
+		{
+			name:            "json with negatives policy",
+			contentType:     "application/json",
+			body:            `{"input": "1,-2,3", "negatives": "abs"}`,
+			expectedFormula: "1+|-2|+3 = 6",
+			expectedTotal:   "6",
+			expectedDropped: 1,
+		},
+		{
+			name:            "json with locale",
+			contentType:     "application/json",
+			body:            `{"input": "1.234,5;0,5", "locale": "de-DE", "maxNumber": 5000}`,
+			expectedFormula: "1234.5+0.5 = 1235",
+			expectedTotal:   "1235",
+		},
+		{
+			name:            "json with formats",
+			contentType:     "application/json",
+			body:            `{"input": "$5,(2),50%", "formats": ["currency", "parentheses", "percent"], "currencies": ["USD"], "allowNegatives": true}`,
+			expectedFormula: "5+-2+0.5 = 3.5",
+			expectedTotal:   "3.5",
+		},
+		{
+			name:            "json with money",
+			contentType:     "application/json",
+			body:            `{"input": "$12.50,3", "money": true}`,
+			expectedFormula: "12.5+3 = 15.50 USD",
+			expectedTotal:   "15.5",
+		},
+		{
+			name:            "json with fractions",
+			contentType:     "application/json",
+			body:            `{"input": "1/3,2/3,5/6", "rational": "mixed"}`,
+			expectedFormula: "1/3+2/3+5/6 = 1 5/6",
+			expectedTotal:   "1.8333333333333333",
+		},
+		{
+			name:            "json with precision",
+			contentType:     "application/json",
+			body:            `{"input": "1.14,1.14", "precision": 1, "rounding": "down", "fixed": true}`,
+			expectedFormula: "1.1+1.1 = 2.2",
+			expectedTotal:   "2.2",
+		},
+		{
+			name:            "json with defaults",
+			contentType:     "application/json",
+			body:            `{"input": "5,6"}`,
+			expectedFormula: "5+6 = 11",
+			expectedTotal:   "11",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := post(t, New(), test.contentType, test.body)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+			var resp struct {
+				Formula string `json:"formula"`
+				Total   string `json:"total"`
+				Terms   []calculate.Term
+				Dropped []calculate.DroppedTerm
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, test.expectedFormula, resp.Formula)
+			assert.Equal(t, test.expectedTotal, resp.Total)
+			assert.Len(t, resp.Dropped, test.expectedDropped)
+		})
+	}
+}
+
+func TestAddErrors(t *testing.T) {
+	tests := []struct {
+		name              string
+		contentType       string
+		body              string
+		expectedStatus    int
+		expectedCode      string
+		expectedPositions []int
+	}{
+		{
+			name:              "negative numbers",
+			contentType:       "text/plain",
+			body:              "1,-2",
+			expectedStatus:    http.StatusUnprocessableEntity,
+			expectedCode:      CodeNegativeNumbers,
+			expectedPositions: []int{2},
+		},
+		{
+			name:              "delimiter syntax",
+			contentType:       "text/plain",
+			body:              "//[*\n1*2",
+			expectedStatus:    http.StatusUnprocessableEntity,
+			expectedCode:      CodeDelimiterSyntax,
+			expectedPositions: []int{2},
+		},
+		{
+			name:              "ambiguous delimiter",
+			contentType:       "text/plain",
+			body:              "//.\n1.2",
+			expectedStatus:    http.StatusUnprocessableEntity,
+			expectedCode:      CodeAmbiguousDelimiter,
+			expectedPositions: []int{2},
+		},
+		{
+			name:              "strict mode invalid token",
+			contentType:       "application/json",
+			body:              `{"input": "1,x", "strict": true}`,
+			expectedStatus:    http.StatusUnprocessableEntity,
+			expectedCode:      CodeInvalidToken,
+			expectedPositions: []int{2},
+		},
+		{
+			name:              "exponent out of range",
+			contentType:       "text/plain",
+			body:              "1,1e-50000000",
+			expectedStatus:    http.StatusUnprocessableEntity,
+			expectedCode:      CodeInvalidToken,
+			expectedPositions: []int{2},
+		},
+		{
+			name:              "fraction exponent out of range",
+			contentType:       "application/json",
+			body:              `{"input": "1/3*10^300000000", "rational": "fraction", "formats": ["scientific"]}`,
+			expectedStatus:    http.StatusUnprocessableEntity,
+			expectedCode:      CodeInvalidToken,
+			expectedPositions: []int{0},
+		},
+		{
+			name:           "bound exponent out of range",
+			contentType:    "application/json",
+			body:           `{"input": "1", "maxNumber": "1e50000000", "maxAction": "clamp"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeInvalidRequest,
+		},
+		{
+			name:              "out of range",
+			contentType:       "application/json",
+			body:              `{"input": "1,20", "maxNumber": 10, "maxAction": "error"}`,
+			expectedStatus:    http.StatusUnprocessableEntity,
+			expectedCode:      CodeOutOfRange,
+			expectedPositions: []int{2},
+		},
+		{
+			name:           "invalid range",
+			contentType:    "application/json",
+			body:           `{"input": "1", "minNumber": 5, "maxNumber": 1}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeInvalidRequest,
+		},
+		{
+			name:           "negative total",
+			contentType:    "application/json",
+			body:           `{"input": "1,-2", "negatives": "error-total"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   CodeNegativeTotal,
+		},
+		{
+			name:           "unknown negatives policy",
+			contentType:    "application/json",
+			body:           `{"input": "1", "negatives": "sometimes"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeInvalidRequest,
+		},
+		{
+			name:           "unknown locale",
+			contentType:    "application/json",
+			body:           `{"input": "1", "locale": "xx-XX"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeInvalidRequest,
+		},
+		{
+			name:           "unknown format",
+			contentType:    "application/json",
+			body:           `{"input": "1", "formats": ["roman"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeInvalidRequest,
+		},
+		{
+			name:              "mixed currencies",
+			contentType:       "application/json",
+			body:              `{"input": "$1,2 EUR", "money": true}`,
+			expectedStatus:    http.StatusUnprocessableEntity,
+			expectedCode:      CodeMixedCurrencies,
+			expectedPositions: []int{3},
+		},
+		{
+			name:           "unknown money currency",
+			contentType:    "application/json",
+			body:           `{"input": "1", "currency": "XYZ"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeInvalidRequest,
+		},
+		{
+			name:           "unknown rational format",
+			contentType:    "application/json",
+			body:           `{"input": "1/3", "rational": "thirds"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeInvalidRequest,
+		},
+		{
+			name:           "unknown rounding mode",
+			contentType:    "application/json",
+			body:           `{"input": "1", "precision": 2, "rounding": "sideways"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeInvalidRequest,
+		},
+		{
+			name:           "too many decimal places",
+			contentType:    "application/json",
+			body:           `{"input": "1", "precision": 100000000, "fixed": true}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeInvalidRequest,
+		},
+		{
+			name:           "malformed json",
+			contentType:    "application/json",
+			body:           `{"input": `,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeInvalidRequest,
+		},
+		{
+			name:           "body too large",
+			contentType:    "text/plain",
+			body:           strings.Repeat("1,", maxBodyBytes),
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedCode:   CodeBodyTooLarge,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := post(t, New(), test.contentType, test.body)
+			assert.Equal(t, test.expectedStatus, rec.Code)
+
+			var resp ErrorResponse
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, test.expectedCode, resp.Error.Code)
+			assert.NotEmpty(t, resp.Error.Message)
+			assert.Equal(t, test.expectedPositions, resp.Error.Positions)
+		})
+	}
+}
+
+func TestAddMethodNotAllowed(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/add", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, http.MethodPost, rec.Header().Get("Allow"))
+}
+
+func TestBaseOptions(t *testing.T) {
+	handler := New(calculate.WithMaxValidNumber(10))
+
+	rec := post(t, handler, "text/plain", "5,20")
+	assert.Contains(t, rec.Body.String(), `"formula":"5+0 = 5"`)
+
+	rec = post(t, handler, "application/json", `{"input": "5,20", "maxNumber": 50}`)
+	assert.Contains(t, rec.Body.String(), `"formula":"5+20 = 25"`)
+
+	// Settings from one request must not carry over to the next.
+	rec = post(t, handler, "text/plain", "5,20")
+	assert.Contains(t, rec.Body.String(), `"formula":"5+0 = 5"`)
+}
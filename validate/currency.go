@@ -0,0 +1,151 @@
+package validate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// currencyMinorUnits holds the ISO 4217 codes that are recognized, with the
+// number of digits in their minor unit.
+var currencyMinorUnits = map[string]int32{
+	"AED": 2, "ARS": 2, "AUD": 2, "BGN": 2, "BHD": 3, "BRL": 2, "CAD": 2,
+	"CHF": 2, "CLP": 0, "CNY": 2, "COP": 2, "CZK": 2, "DKK": 2, "EGP": 2,
+	"EUR": 2, "GBP": 2, "HKD": 2, "HUF": 2, "IDR": 2, "ILS": 2, "INR": 2,
+	"ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0, "KWD": 3, "MXN": 2, "MYR": 2,
+	"NOK": 2, "NZD": 2, "OMR": 3, "PHP": 2, "PLN": 2, "RON": 2, "RUB": 2,
+	"SAR": 2, "SEK": 2, "SGD": 2, "THB": 2, "TND": 3, "TRY": 2, "TWD": 2,
+	"UAH": 2, "USD": 2, "VND": 0, "ZAR": 2,
+}
+
+// currencySymbols maps each recognized symbol to the code it stands for.
+// "$" is taken to be the US dollar.
+var currencySymbols = map[string]string{
+	"$": "USD", "€": "EUR", "£": "GBP", "¥": "JPY", "₹": "INR", "₩": "KRW",
+	"₽": "RUB", "₺": "TRY", "₪": "ILS", "₫": "VND", "฿": "THB", "₱": "PHP",
+}
+
+// CurrencyMinorUnits returns the number of digits in the minor unit of the
+// currency with the ISO 4217 code, and whether the code is recognized.
+func CurrencyMinorUnits(code string) (int32, bool) {
+	digits, ok := currencyMinorUnits[code]
+	return digits, ok
+}
+
+// WithCurrency accepts numbers written with a currency, enabling the
+// currency format unless WithFormats already has.
+func WithCurrency() Option {
+	return func(v *Validator) {
+		v.currency = true
+	}
+}
+
+// hasCurrencyFormat reports whether formats include the currency format.
+func hasCurrencyFormat(formats []Format) bool {
+	for _, format := range formats {
+		if _, ok := format.(currencyFormat); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type currencyFormat struct {
+	// accepted limits the codes accepted; nil accepts every one.
+	accepted map[string]bool
+}
+
+// Currency accepts numbers written with a currency symbol or ISO 4217 code
+// before or after them, such as $1.50, € 3.50 or 12 EUR. Given codes, it
+// only accepts those currencies, and numbers in other currencies are
+// invalid.
+func Currency(codes ...string) (Format, error) {
+	f := currencyFormat{}
+	for _, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if _, ok := currencyMinorUnits[code]; !ok {
+			return nil, fmt.Errorf("unknown currency code: %q", code)
+		}
+		if f.accepted == nil {
+			f.accepted = make(map[string]bool)
+		}
+		f.accepted[code] = true
+	}
+	return f, nil
+}
+
+func (f currencyFormat) Recognize(text string) (Match, bool, error) {
+	sign := ""
+	if text != "" && (text[0] == '-' || text[0] == '+') {
+		sign, text = text[:1], strings.TrimSpace(text[1:])
+	}
+
+	code, rest := currencyPrefix(text)
+	if code == "" {
+		code, rest = currencySuffix(text)
+	}
+	if code == "" {
+		return Match{}, false, nil
+	}
+	if _, ok := currencyMinorUnits[code]; !ok {
+		return Match{}, false, fmt.Errorf("unknown currency code %q", code)
+	}
+	if f.accepted != nil && !f.accepted[code] {
+		return Match{}, false, fmt.Errorf("currency %s is not accepted", code)
+	}
+	return Match{Text: sign + strings.TrimSpace(rest), Currency: code}, true, nil
+}
+
+// currencyPrefix returns the code of the symbol or code text starts with,
+// and the rest of text.
+func currencyPrefix(text string) (string, string) {
+	for symbol, code := range currencySymbols {
+		if strings.HasPrefix(text, symbol) {
+			return code, text[len(symbol):]
+		}
+	}
+	if len(text) > 3 && isCurrencyCode(text[:3]) {
+		if r, _ := utf8.DecodeRuneInString(text[3:]); !unicode.IsLetter(r) {
+			return text[:3], text[3:]
+		}
+	}
+	return "", text
+}
+
+// currencySuffix returns the code of the symbol or code text ends with, and
+// the rest of text.
+func currencySuffix(text string) (string, string) {
+	for symbol, code := range currencySymbols {
+		if strings.HasSuffix(text, symbol) {
+			return code, text[:len(text)-len(symbol)]
+		}
+	}
+	if n := len(text); n > 3 && isCurrencyCode(text[n-3:]) {
+		if r, _ := utf8.DecodeLastRuneInString(text[:n-3]); !unicode.IsLetter(r) {
+			return text[n-3:], text[:n-3]
+		}
+	}
+	return "", text
+}
+
+func isCurrencyCode(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func (f currencyFormat) Markup() []string {
+	symbols := make([]string, 0, len(currencySymbols))
+	for symbol := range currencySymbols {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+func (currencyFormat) Example() string { return "$1.50" }
@@ -0,0 +1,223 @@
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// numberSyntax gives the characters that can appear in a number besides
+// digits, signs and exponents: the decimal mark, the group separator when it
+// is not also the delimiter, and the fraction bar and space of mixed numbers
+// when fractions are enabled. formats add their own markup.
+type numberSyntax struct {
+	decimalMark    string
+	groupSeparator string
+	fractions      bool
+	formats        []Format
+}
+
+func (v *Validator) numberSyntax() numberSyntax {
+	syntax := numberSyntax{decimalMark: ".", fractions: v.fractions, formats: v.formats}
+	if v.locale != nil {
+		syntax.decimalMark = v.locale.DecimalMark
+		if v.locale.GroupSeparator != v.locale.Delimiter {
+			syntax.groupSeparator = v.locale.GroupSeparator
+		}
+	}
+	return syntax
+}
+
+// units returns the digits, signs, exponents and separators that make up a
+// number.
+func (n numberSyntax) units() []string {
+	units := strings.Split("0123456789+-eE", "")
+	units = append(units, n.decimalMark)
+	if n.groupSeparator != "" {
+		units = append(units, n.groupSeparator)
+	}
+	if n.fractions {
+		units = append(units, "/", " ")
+	}
+	return units
+}
+
+// unitAt returns the unit that s starts with, or "".
+func (n numberSyntax) unitAt(s string) string {
+	for _, unit := range n.units() {
+		if strings.HasPrefix(s, unit) {
+			return unit
+		}
+	}
+	return ""
+}
+
+// ambiguousForms returns the number forms that delimiter can be confused
+// with, or nil if it is safe to use.
+//
+// A delimiter made only of number characters can match inside a number. A
+// delimiter that starts with a digit or decimal mark can swallow the end of
+// the number before it, and one that ends with a digit, decimal mark or sign
+// can swallow the start of the number after it.
+func (n numberSyntax) ambiguousForms(delimiter string) []string {
+	if delimiter == "" {
+		return nil
+	}
+
+	var conflicts []string
+	for rest := delimiter; rest != ""; {
+		unit := n.unitAt(rest)
+		if unit == "" {
+			conflicts = nil
+			break
+		}
+		conflicts = append(conflicts, unit)
+		rest = rest[len(unit):]
+	}
+	if conflicts == nil {
+		if first := delimiter[0]; first >= '0' && first <= '9' {
+			conflicts = append(conflicts, string(first))
+		} else if strings.HasPrefix(delimiter, n.decimalMark) {
+			conflicts = append(conflicts, n.decimalMark)
+		}
+		if last := delimiter[len(delimiter)-1]; strings.IndexByte("0123456789+-", last) != -1 {
+			conflicts = append(conflicts, string(last))
+		} else if strings.HasSuffix(delimiter, n.decimalMark) {
+			conflicts = append(conflicts, n.decimalMark)
+		}
+	}
+
+	var forms []string
+	for _, unit := range conflicts {
+		if form := n.form(unit); !containsString(forms, form) {
+			forms = append(forms, form)
+		}
+	}
+	for _, format := range n.formats {
+		for _, markup := range format.Markup() {
+			if strings.Contains(markup, delimiter) || strings.Contains(delimiter, markup) {
+				forms = appendFormatForm(forms, format)
+			}
+		}
+	}
+	return forms
+}
+
+func appendFormatForm(forms []string, format Format) []string {
+	form := fmt.Sprintf("numbers written as %s", format.Example())
+	if containsString(forms, form) {
+		return forms
+	}
+	return append(forms, form)
+}
+
+// patternForms returns the number forms that pattern can be confused with:
+// those containing a number character the pattern matches on its own.
+func (n numberSyntax) patternForms(pattern *regexp.Regexp) []string {
+	anchored := regexp.MustCompile(`^(?:` + pattern.String() + `)`)
+
+	var forms []string
+	for _, unit := range n.units() {
+		if match := anchored.FindStringIndex(unit); match == nil || match[1] == 0 {
+			continue
+		}
+		form := n.form(unit)
+		if unit >= "0" && unit <= "9" {
+			form = "numbers containing digits"
+		}
+		if !containsString(forms, form) {
+			forms = append(forms, form)
+		}
+	}
+	for _, format := range n.formats {
+		for _, markup := range format.Markup() {
+			if match := anchored.FindStringIndex(markup); match != nil && match[1] > 0 {
+				forms = appendFormatForm(forms, format)
+			}
+		}
+	}
+	return forms
+}
+
+func (n numberSyntax) form(unit string) string {
+	switch {
+	case unit == "-":
+		return "negative numbers such as -5"
+	case unit == "+":
+		return "signed numbers such as +5"
+	case unit == n.decimalMark:
+		return fmt.Sprintf("decimals such as 1%s5 or %s5", unit, unit)
+	case unit == n.groupSeparator:
+		return fmt.Sprintf("grouped numbers such as 1%s234", unit)
+	case unit == "e" || unit == "E":
+		return fmt.Sprintf("exponents such as 1%s3", unit)
+	case unit == "/":
+		return "fractions such as 2/3"
+	case unit == " " && n.fractions:
+		return "mixed numbers such as 1 1/2"
+	}
+	return fmt.Sprintf("numbers containing the digit %s", unit)
+}
+
+// overlapWarnings describes every pair of delimiters where one contains the
+// other. Both still work, but the longer one is matched first, so the
+// shorter one does not split text that is part of the longer one.
+func overlapWarnings(delimiters []string) []string {
+	var warnings []string
+	for i, outer := range delimiters {
+		for j, inner := range delimiters {
+			if i == j || inner == "" || outer == inner || !strings.Contains(outer, inner) {
+				continue
+			}
+			warning := fmt.Sprintf("delimiter %q contains delimiter %q; the longer one is matched first", outer, inner)
+			if !containsString(warnings, warning) {
+				warnings = append(warnings, warning)
+			}
+		}
+	}
+	return warnings
+}
+
+// CheckDelimiters checks the configured delimiters and patterns, before any
+// custom header is applied. It returns an AmbiguousDelimiterError for a
+// delimiter that overlaps with number syntax, and a warning for each pair of
+// delimiters where one contains the other. An invalid locale is reported
+// as ErrInvalidLocale.
+func (v *Validator) CheckDelimiters() ([]string, error) {
+	return v.checkDelimiters(v.patterns, nil, nil, nil)
+}
+
+// checkDelimiters checks the delimiters declared in a header together with
+// the configured ones. customOffsets holds the offset of each custom
+// delimiter in the input.
+func (v *Validator) checkDelimiters(configuredPatterns []*regexp.Regexp, custom []string, customOffsets []int, customPattern *regexp.Regexp) ([]string, error) {
+	if v.locale != nil {
+		if err := v.locale.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	syntax := v.numberSyntax()
+	configured := v.delimiters
+	for i, delimiter := range custom {
+		if forms := syntax.ambiguousForms(delimiter); forms != nil {
+			return nil, &AmbiguousDelimiterError{Delimiter: delimiter, Offset: customOffsets[i], Forms: forms}
+		}
+	}
+	if customPattern != nil {
+		if forms := syntax.patternForms(customPattern); forms != nil {
+			return nil, &AmbiguousDelimiterError{Delimiter: customPattern.String(), Offset: len(regexHeaderPrefix), Forms: forms}
+		}
+	}
+	for _, delimiter := range configured {
+		if forms := syntax.ambiguousForms(delimiter); forms != nil {
+			return nil, &AmbiguousDelimiterError{Delimiter: delimiter, Offset: -1, Forms: forms}
+		}
+	}
+	for _, pattern := range configuredPatterns {
+		if forms := syntax.patternForms(pattern); forms != nil {
+			return nil, &AmbiguousDelimiterError{Delimiter: pattern.String(), Offset: -1, Forms: forms}
+		}
+	}
+	return overlapWarnings(append(append([]string{}, custom...), configured...)), nil
+}
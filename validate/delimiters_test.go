@@ -0,0 +1,133 @@
+package validate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAmbiguousForms(t *testing.T) {
+	tests := []struct {
+		name      string
+		delimiter string
+		expected  []string
+	}{
+		{
+			name:      "safe single character",
+			delimiter: ";",
+			expected:  nil,
+		},
+		{
+			name:      "digit in the middle",
+			delimiter: "x1x",
+			expected:  nil,
+		},
+		{
+			name:      "minus sign",
+			delimiter: "-",
+			expected:  []string{"negative numbers such as -5"},
+		},
+		{
+			name:      "decimal point",
+			delimiter: ".",
+			expected:  []string{"decimals such as 1.5 or .5"},
+		},
+		{
+			name:      "exponent marker",
+			delimiter: "e",
+			expected:  []string{"exponents such as 1e3"},
+		},
+		{
+			name:      "only number characters",
+			delimiter: "+.",
+			expected:  []string{"signed numbers such as +5", "decimals such as 1.5 or .5"},
+		},
+		{
+			name:      "starts with a digit",
+			delimiter: "7x",
+			expected:  []string{"numbers containing the digit 7"},
+		},
+		{
+			name:      "ends with a minus sign",
+			delimiter: "x-",
+			expected:  []string{"negative numbers such as -5"},
+		},
+		{
+			name:      "starts with a letter e",
+			delimiter: "ex",
+			expected:  nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, New().numberSyntax().ambiguousForms(test.delimiter))
+		})
+	}
+}
+
+func TestAmbiguousDelimiterError(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           []Option
+		input          string
+		expectedErr    string
+		expectedOffset int
+	}{
+		{
+			name:           "single character header",
+			input:          "//-\n1-2",
+			expectedErr:    "ambiguous delimiter \"-\": it can be confused with negative numbers such as -5",
+			expectedOffset: 2,
+		},
+		{
+			name:           "bracketed header",
+			input:          "//[;][..]\n1..2",
+			expectedErr:    "ambiguous delimiter \"..\": it can be confused with decimals such as 1.5 or .5",
+			expectedOffset: 6,
+		},
+		{
+			name:           "configured delimiter",
+			opts:           []Option{WithDefaultDelimiter(".")},
+			input:          "1.2",
+			expectedErr:    "ambiguous delimiter \".\": it can be confused with decimals such as 1.5 or .5",
+			expectedOffset: -1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := New(test.opts...).ValidateInput(test.input)
+			assert.EqualError(t, err, test.expectedErr)
+			assert.True(t, errors.Is(err, ErrAmbiguousDelimiter))
+
+			var ambiguousErr *AmbiguousDelimiterError
+			assert.True(t, errors.As(err, &ambiguousErr))
+			assert.Equal(t, test.expectedOffset, ambiguousErr.Offset)
+		})
+	}
+}
+
+func TestOverlapWarnings(t *testing.T) {
+	parsed, err := New().Parse("//[;;][;]\n1;;2;3")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"delimiter \";;\" contains delimiter \";\"; the longer one is matched first"}, parsed.Warnings)
+
+	parsed, err = New().Parse("//[*]\n1*2,3")
+	assert.NoError(t, err)
+	assert.Empty(t, parsed.Warnings)
+}
+
+func TestCheckDelimiters(t *testing.T) {
+	warnings, err := New().CheckDelimiters()
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	warnings, err = New(WithDefaultDelimiter(",,")).CheckDelimiters()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"delimiter \",,\" contains delimiter \",\"; the longer one is matched first"}, warnings)
+
+	_, err = New(WithDelimiters(",", "-")).CheckDelimiters()
+	assert.True(t, errors.Is(err, ErrAmbiguousDelimiter))
+}
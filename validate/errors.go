@@ -0,0 +1,128 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Sentinels for use with errors.Is. Each error type below matches one of them.
+var (
+	ErrNegativeNumbers    = errors.New("negative numbers found")
+	ErrDelimiterSyntax    = errors.New("invalid delimiter syntax")
+	ErrInvalidToken       = errors.New("invalid token")
+	ErrAmbiguousDelimiter = errors.New("ambiguous delimiter")
+)
+
+// NegativeNumbersError lists the negative numbers found in input that does
+// not allow them. Positions holds the byte offset of each value in the input.
+type NegativeNumbersError struct {
+	Values    []decimal.Decimal
+	Positions []int
+}
+
+func (e *NegativeNumbersError) Error() string {
+	return fmt.Sprintf("invalid input: negative numbers found: %s", strings.Join(findNegativeNumbers(e.Values), ", "))
+}
+
+func (e *NegativeNumbersError) Is(target error) bool {
+	return target == ErrNegativeNumbers
+}
+
+// DelimiterSyntaxError reports a malformed custom delimiter header. Offset is
+// the byte offset in the input where the problem starts.
+type DelimiterSyntaxError struct {
+	Offset    int
+	Delimiter string
+	Reason    string
+}
+
+func (e *DelimiterSyntaxError) Error() string {
+	if e.Delimiter != "" {
+		return fmt.Sprintf("invalid custom delimiter: %q", e.Delimiter)
+	}
+	return fmt.Sprintf("invalid delimiter format: %s", e.Reason)
+}
+
+func (e *DelimiterSyntaxError) Is(target error) bool {
+	return target == ErrDelimiterSyntax
+}
+
+// AmbiguousDelimiterError reports a delimiter that overlaps with number
+// syntax. Forms describes the numbers it could be confused with. Offset is
+// the byte offset of the delimiter in the input when it was declared in a
+// header, and -1 when it comes from the configuration.
+type AmbiguousDelimiterError struct {
+	Delimiter string
+	Offset    int
+	Forms     []string
+}
+
+func (e *AmbiguousDelimiterError) Error() string {
+	return fmt.Sprintf("ambiguous delimiter %q: it can be confused with %s", e.Delimiter, strings.Join(e.Forms, ", "))
+}
+
+func (e *AmbiguousDelimiterError) Is(target error) bool {
+	return target == ErrAmbiguousDelimiter
+}
+
+// InvalidTokenError describes a token that could not be read as a number.
+// Rejected tokens, such as numbers beyond WithMaxExponent, are an error even
+// outside strict mode.
+type InvalidTokenError struct {
+	Token    string `json:"token"`
+	Index    int    `json:"index"`
+	Offset   int    `json:"offset"`
+	Reason   string `json:"reason"`
+	Rejected bool   `json:"rejected,omitempty"`
+}
+
+func newInvalidTokenError(token string, index, offset int, err error) *InvalidTokenError {
+	return &InvalidTokenError{
+		Token:    token,
+		Index:    index,
+		Offset:   offset,
+		Reason:   err.Error(),
+		Rejected: errors.Is(err, errExponentRange) || errors.Is(err, errFractionRange),
+	}
+}
+
+func (e *InvalidTokenError) Error() string {
+	return fmt.Sprintf("%q at index %d (offset %d): %s", e.Token, e.Index, e.Offset, e.Reason)
+}
+
+func (e *InvalidTokenError) Is(target error) bool {
+	return target == ErrInvalidToken
+}
+
+// InvalidTokensError is returned in strict mode and lists every invalid token.
+type InvalidTokensError struct {
+	Tokens []*InvalidTokenError
+}
+
+func (e *InvalidTokensError) Error() string {
+	details := make([]string, len(e.Tokens))
+	for i, token := range e.Tokens {
+		details[i] = token.Error()
+	}
+	return fmt.Sprintf("invalid input: invalid tokens found: %s", strings.Join(details, "; "))
+}
+
+func (e *InvalidTokensError) Unwrap() []error {
+	errs := make([]error, len(e.Tokens))
+	for i, token := range e.Tokens {
+		errs[i] = token
+	}
+	return errs
+}
+
+// Offsets returns the byte offset of every invalid token.
+func (e *InvalidTokensError) Offsets() []int {
+	offsets := make([]int, len(e.Tokens))
+	for i, token := range e.Tokens {
+		offsets[i] = token.Offset
+	}
+	return offsets
+}
@@ -0,0 +1,70 @@
+package validate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegativeNumbersError(t *testing.T) {
+	_, err := New().ValidateInput("//;\n1; -2;3;-4.5")
+
+	assert.EqualError(t, err, "invalid input: negative numbers found: -2, -4.5")
+	assert.True(t, errors.Is(err, ErrNegativeNumbers))
+
+	var negativeErr *NegativeNumbersError
+	assert.True(t, errors.As(err, &negativeErr))
+	assert.Equal(t, []decimal.Decimal{decimal.NewFromInt(-2), decimal.RequireFromString("-4.5")}, negativeErr.Values)
+	assert.Equal(t, []int{7, 12}, negativeErr.Positions)
+}
+
+func TestDelimiterSyntaxError(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedErr    string
+		expectedOffset int
+	}{
+		{
+			name:           "missing closing bracket",
+			input:          "//[*][!!\n1*2",
+			expectedErr:    "invalid delimiter format: missing closing bracket",
+			expectedOffset: 5,
+		},
+		{
+			name:           "multi-character delimiter without brackets",
+			input:          "//**\n1**2",
+			expectedErr:    "invalid custom delimiter: \"**\"",
+			expectedOffset: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := New().ValidateInput(test.input)
+			assert.EqualError(t, err, test.expectedErr)
+			assert.True(t, errors.Is(err, ErrDelimiterSyntax))
+
+			var syntaxErr *DelimiterSyntaxError
+			assert.True(t, errors.As(err, &syntaxErr))
+			assert.Equal(t, test.expectedOffset, syntaxErr.Offset)
+		})
+	}
+}
+
+func TestInvalidTokensError(t *testing.T) {
+	_, err := New(WithStrict(true)).ValidateInput("1,x,3,y")
+
+	assert.True(t, errors.Is(err, ErrInvalidToken))
+	assert.False(t, errors.Is(err, ErrNegativeNumbers))
+
+	var tokensErr *InvalidTokensError
+	assert.True(t, errors.As(err, &tokensErr))
+	assert.Equal(t, []int{2, 6}, tokensErr.Offsets())
+
+	var tokenErr *InvalidTokenError
+	assert.True(t, errors.As(err, &tokenErr))
+	assert.Equal(t, "x", tokenErr.Token)
+}
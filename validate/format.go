@@ -0,0 +1,199 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Format is a way of writing numbers that is not accepted on its own, such
+// as "12%". Formats are enabled with WithFormats and tried on every token.
+type Format interface {
+	// Recognize reports whether text is written in the format. If it is, the
+	// Match holds text without the format's markup. An error means text is
+	// written in the format but cannot be accepted.
+	Recognize(text string) (Match, bool, error)
+	// Markup lists the text the format adds to a number. A delimiter that
+	// overlaps with it is ambiguous.
+	Markup() []string
+	// Example is a number written in the format, used in error messages.
+	Example() string
+}
+
+// Match is what a Format found in a token.
+type Match struct {
+	// Text is the token without the format's markup.
+	Text string
+	// Negative is set when the markup makes the number negative.
+	Negative bool
+	// Exponent moves the decimal point: -2 for a percentage.
+	Exponent int32
+	// Currency is the ISO 4217 code of the currency written with the number.
+	Currency string
+}
+
+var errNegativeTwice = errors.New("negative number marked negative again")
+
+// WithFormats accepts numbers written in the given formats, in addition to
+// plain ones.
+func WithFormats(formats ...Format) Option {
+	return func(v *Validator) {
+		v.formats = append(v.formats, formats...)
+	}
+}
+
+// ParseFormats returns the built-in formats with the given names: currency,
+// parentheses, trailing-minus, percent and scientific. currencies limits the
+// currency format to those codes, and needs it to be named.
+func ParseFormats(names []string, currencies ...string) ([]Format, error) {
+	var formats []Format
+	hasCurrency := false
+	for _, name := range names {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "currency":
+			format, err := Currency(currencies...)
+			if err != nil {
+				return nil, err
+			}
+			formats = append(formats, format)
+			hasCurrency = true
+		case "parentheses", "parens":
+			formats = append(formats, Parentheses())
+		case "trailing-minus":
+			formats = append(formats, TrailingMinus())
+		case "percent":
+			formats = append(formats, Percent())
+		case "scientific":
+			formats = append(formats, Scientific())
+		default:
+			return nil, fmt.Errorf("unknown number format: %q", name)
+		}
+	}
+	if len(currencies) > 0 && !hasCurrency {
+		return nil, errors.New("currency codes need the currency format")
+	}
+	return formats, nil
+}
+
+// recognize removes the markup of every format text is written in. Each
+// format is applied at most once, so "((5))" is not a number.
+func recognize(formats []Format, text string) (Match, error) {
+	var combined Match
+	used := make([]bool, len(formats))
+	for found := true; found; {
+		found = false
+		for i, format := range formats {
+			if used[i] {
+				continue
+			}
+			match, ok, err := format.Recognize(text)
+			if err != nil {
+				return Match{}, err
+			}
+			if !ok {
+				continue
+			}
+			if match.Negative && combined.Negative {
+				return Match{}, errNegativeTwice
+			}
+			used[i], found = true, true
+			text = strings.TrimSpace(match.Text)
+			combined.Negative = combined.Negative || match.Negative
+			combined.Exponent += match.Exponent
+			if match.Currency != "" {
+				combined.Currency = match.Currency
+			}
+		}
+	}
+	combined.Text = text
+	return combined, nil
+}
+
+// apply gives number the sign and exponent of the match.
+func (m Match) apply(number decimal.Decimal) (decimal.Decimal, error) {
+	if m.Negative {
+		if number.Sign() == -1 {
+			return decimal.Zero, errNegativeTwice
+		}
+		number = number.Neg()
+	}
+	if m.Exponent != 0 {
+		number = number.Shift(m.Exponent)
+	}
+	return number, nil
+}
+
+type parenthesesFormat struct{}
+
+// Parentheses accepts negative numbers written in parentheses, as in
+// accounting: (45.10) is -45.10.
+func Parentheses() Format { return parenthesesFormat{} }
+
+func (parenthesesFormat) Recognize(text string) (Match, bool, error) {
+	if len(text) < 2 || text[0] != '(' || text[len(text)-1] != ')' {
+		return Match{}, false, nil
+	}
+	return Match{Text: text[1 : len(text)-1], Negative: true}, true, nil
+}
+
+func (parenthesesFormat) Markup() []string { return []string{"(", ")"} }
+func (parenthesesFormat) Example() string  { return "(5)" }
+
+type trailingMinusFormat struct{}
+
+// TrailingMinus accepts negative numbers with the minus sign after them:
+// 45.10- is -45.10.
+func TrailingMinus() Format { return trailingMinusFormat{} }
+
+func (trailingMinusFormat) Recognize(text string) (Match, bool, error) {
+	if len(text) < 2 || text[len(text)-1] != '-' {
+		return Match{}, false, nil
+	}
+	return Match{Text: text[:len(text)-1], Negative: true}, true, nil
+}
+
+func (trailingMinusFormat) Markup() []string { return []string{"-"} }
+func (trailingMinusFormat) Example() string  { return "5-" }
+
+type percentFormat struct{}
+
+// Percent accepts percentages: 12% is 0.12.
+func Percent() Format { return percentFormat{} }
+
+func (percentFormat) Recognize(text string) (Match, bool, error) {
+	if len(text) < 2 || text[len(text)-1] != '%' {
+		return Match{}, false, nil
+	}
+	return Match{Text: text[:len(text)-1], Exponent: -2}, true, nil
+}
+
+func (percentFormat) Markup() []string { return []string{"%"} }
+func (percentFormat) Example() string  { return "12%" }
+
+type scientificFormat struct{}
+
+// scientificPattern matches a power of ten written out, as in 1.2×10^3.
+var scientificPattern = regexp.MustCompile(`^(.+?)\s*[×xX*]\s*10\^([+-]?[0-9]+)$`)
+
+// Scientific accepts powers of ten written out: 1.2×10^3, 1.2x10^3 and
+// 1.2*10^3 are all 1200. The e notation, 1.2e3, is always accepted.
+func Scientific() Format { return scientificFormat{} }
+
+func (scientificFormat) Recognize(text string) (Match, bool, error) {
+	m := scientificPattern.FindStringSubmatch(text)
+	if m == nil {
+		return Match{}, false, nil
+	}
+	exponent, err := strconv.ParseInt(m[2], 10, 32)
+	if err != nil {
+		return Match{}, false, errNotANumber
+	}
+	return Match{Text: m[1], Exponent: int32(exponent)}, true, nil
+}
+
+func (scientificFormat) Markup() []string { return []string{"×10^", "x10^", "X10^", "*10^"} }
+func (scientificFormat) Example() string  { return "1.2×10^3" }
@@ -0,0 +1,133 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFormats(t *testing.T) {
+	formats, err := ParseFormats([]string{"currency", "Parens", "trailing-minus", "percent", "scientific"})
+	assert.NoError(t, err)
+	assert.Len(t, formats, 5)
+
+	_, err = ParseFormats([]string{"roman"})
+	assert.EqualError(t, err, `unknown number format: "roman"`)
+
+	_, err = ParseFormats([]string{"currency"}, "usd", "XXY")
+	assert.EqualError(t, err, `unknown currency code: "XXY"`)
+
+	_, err = ParseFormats([]string{"percent"}, "USD")
+	assert.EqualError(t, err, "currency codes need the currency format")
+}
+
+func TestFormats(t *testing.T) {
+	all, err := ParseFormats([]string{"currency", "parentheses", "trailing-minus", "percent", "scientific"})
+	assert.NoError(t, err)
+	dollarsAndEuros, err := Currency("USD", "EUR")
+	assert.NoError(t, err)
+	enUS, _ := LookupLocale("en-US")
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		input    string
+		expected string
+		reason   string
+	}{
+		{name: "not enabled", input: "$5", expected: "0", reason: "not a number"},
+		{name: "currency symbol", opts: []Option{WithFormats(all...)}, input: "$1200.00", expected: "1200"},
+		{name: "currency symbol with space", opts: []Option{WithFormats(all...)}, input: "€ 3.50", expected: "3.5"},
+		{name: "currency symbol after", opts: []Option{WithFormats(all...)}, input: "3.50 €", expected: "3.5"},
+		{name: "currency code", opts: []Option{WithFormats(all...)}, input: "12 EUR", expected: "12"},
+		{name: "currency code before", opts: []Option{WithFormats(all...)}, input: "CHF7", expected: "7"},
+		{name: "sign before symbol", opts: []Option{WithFormats(all...)}, input: "-$5", expected: "-5"},
+		{name: "unknown code", opts: []Option{WithFormats(all...)}, input: "5 ABC", expected: "0", reason: `unknown currency code "ABC"`},
+		{name: "accepted code", opts: []Option{WithFormats(dollarsAndEuros)}, input: "5 USD", expected: "5"},
+		{name: "code not accepted", opts: []Option{WithFormats(dollarsAndEuros)}, input: "£5", expected: "0", reason: "currency GBP is not accepted"},
+		{name: "grouped currency", opts: []Option{WithFormats(all...), WithLocale(enUS)}, input: `"$1,200.00"`, expected: "1200"},
+		{name: "parentheses", opts: []Option{WithFormats(all...)}, input: "(45.10)", expected: "-45.1"},
+		{name: "parenthesized currency", opts: []Option{WithFormats(all...)}, input: "($45.10)", expected: "-45.1"},
+		{name: "nested parentheses", opts: []Option{WithFormats(all...)}, input: "((5))", expected: "0", reason: "not a number"},
+		{name: "parenthesized negative", opts: []Option{WithFormats(all...)}, input: "(-5)", expected: "0", reason: "negative number marked negative again"},
+		{name: "parenthesized trailing minus", opts: []Option{WithFormats(all...)}, input: "(5-)", expected: "0", reason: "negative number marked negative again"},
+		{name: "trailing minus", opts: []Option{WithFormats(all...)}, input: "45.10-", expected: "-45.1"},
+		{name: "percent", opts: []Option{WithFormats(all...)}, input: "12%", expected: "0.12"},
+		{name: "negative percent", opts: []Option{WithFormats(all...)}, input: "(12.5%)", expected: "-0.125"},
+		{name: "scientific", opts: []Option{WithFormats(all...)}, input: "1.2×10^3", expected: "1200"},
+		{name: "scientific x", opts: []Option{WithFormats(all...)}, input: "-1.2 x 10^-2", expected: "-0.012"},
+		{name: "e notation", input: "-1.2e3", expected: "-1200"},
+		{name: "markup alone", opts: []Option{WithFormats(all...)}, input: "$", expected: "0", reason: "missing number"},
+		{name: "only the enabled format", opts: []Option{WithFormats(Percent())}, input: "(5)", expected: "0", reason: "not a number"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := New(append(test.opts, WithAllowNegatives(true), WithDelimiters("\n"))...)
+			parsed, err := v.Parse(test.input)
+			assert.NoError(t, err)
+			streamed, err := parseStreamAll(v, test.input)
+			assert.NoError(t, err)
+
+			for _, p := range []*Parsed{parsed, streamed} {
+				assert.Equal(t, test.expected, p.Values[0].String())
+				if test.reason == "" {
+					assert.Empty(t, p.InvalidTokens)
+				} else if assert.Len(t, p.InvalidTokens, 1) {
+					assert.Equal(t, test.reason, p.InvalidTokens[0].Reason)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatDelimiters(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      Format
+		input       string
+		expectedErr string
+	}{
+		{name: "percent header", format: Percent(), input: "//%\n1%2", expectedErr: `ambiguous delimiter "%": it can be confused with numbers written as 12%`},
+		{name: "parenthesis header", format: Parentheses(), input: "//[)]\n1)2", expectedErr: `ambiguous delimiter ")": it can be confused with numbers written as (5)`},
+		{name: "multiplication header", format: Scientific(), input: "//*\n1*2", expectedErr: `ambiguous delimiter "*": it can be confused with numbers written as 1.2×10^3`},
+		{name: "percent header without the format", format: Parentheses(), input: "//%\n1%2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := New(WithFormats(test.format))
+			_, err := v.Parse(test.input)
+			_, streamErr := parseStreamAll(v, test.input)
+			if test.expectedErr == "" {
+				assert.NoError(t, err)
+				assert.NoError(t, streamErr)
+				return
+			}
+			assert.EqualError(t, err, test.expectedErr)
+			assert.EqualError(t, streamErr, test.expectedErr)
+		})
+	}
+}
+
+func TestCurrencies(t *testing.T) {
+	parsed, err := New().Parse("$1,2")
+	assert.NoError(t, err)
+	assert.Nil(t, parsed.Currencies)
+
+	for _, workers := range []int{1, 4} {
+		v := New(WithCurrency(), WithWorkers(workers))
+		parsed, err := v.Parse("$1,2 EUR,3")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"USD", "EUR", ""}, parsed.Currencies)
+
+		var streamed []string
+		_, err = v.ParseStream(strings.NewReader("$1,2 EUR,3"), func(value Value) error {
+			streamed = append(streamed, value.Currency)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, parsed.Currencies, streamed)
+	}
+}
@@ -0,0 +1,94 @@
+package validate
+
+import (
+	"errors"
+	"math/big"
+	"regexp"
+
+	"github.com/shopspring/decimal"
+)
+
+// fractionPlaces is the number of decimal places kept in the Value of a
+// fraction. The exact value is kept alongside it.
+const fractionPlaces = 16
+
+var (
+	errZeroDenominator = errors.New("fraction with a zero denominator")
+	errFractionRange   = errors.New("fraction out of range")
+)
+
+// fractionPattern matches a fraction such as 2/3, or a mixed number such as
+// 1 1/2.
+var fractionPattern = regexp.MustCompile(`^([+-]?)(?:([0-9]+)\s+)?([0-9]+)/([0-9]+)$`)
+
+// WithFractions accepts numbers written as fractions, such as 2/3, and mixed
+// numbers, such as 1 1/2. Their exact value is returned in Parsed.Fractions
+// and Value.Fraction, and "/" can no longer be a delimiter.
+func WithFractions() Option {
+	return func(v *Validator) {
+		v.fractions = true
+	}
+}
+
+// parseFraction reports whether text is written as a fraction and, if it
+// is, returns its value. A maxDigits above 0 limits the digits in each part.
+func parseFraction(text string, maxDigits int32) (*big.Rat, bool, error) {
+	m := fractionPattern.FindStringSubmatch(text)
+	if m == nil {
+		return nil, false, nil
+	}
+	if maxDigits > 0 {
+		for _, part := range m[2:] {
+			if len(part) > int(maxDigits) {
+				return nil, false, errFractionRange
+			}
+		}
+	}
+
+	numerator, _ := new(big.Int).SetString(m[3], 10)
+	denominator, _ := new(big.Int).SetString(m[4], 10)
+	if denominator.Sign() == 0 {
+		return nil, false, errZeroDenominator
+	}
+	fraction := new(big.Rat).SetFrac(numerator, denominator)
+	if m[2] != "" {
+		whole, _ := new(big.Int).SetString(m[2], 10)
+		fraction.Add(fraction, new(big.Rat).SetInt(whole))
+	}
+	if m[1] == "-" {
+		fraction.Neg(fraction)
+	}
+	return fraction, true, nil
+}
+
+// fractionValue rounds fraction to fractionPlaces decimal places.
+func fractionValue(fraction *big.Rat) decimal.Decimal {
+	return decimal.NewFromBigRat(fraction, fractionPlaces)
+}
+
+// applyFraction gives fraction the sign and exponent of the match, as apply
+// does for decimals.
+func (m Match) applyFraction(fraction *big.Rat) (*big.Rat, error) {
+	if m.Negative {
+		if fraction.Sign() == -1 {
+			return nil, errNegativeTwice
+		}
+		fraction.Neg(fraction)
+	}
+	if m.Exponent != 0 {
+		shift := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs32(m.Exponent))), nil))
+		if m.Exponent > 0 {
+			fraction.Mul(fraction, shift)
+		} else {
+			fraction.Quo(fraction, shift)
+		}
+	}
+	return fraction, nil
+}
+
+func abs32(n int32) int32 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
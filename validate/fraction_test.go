@@ -0,0 +1,103 @@
+package validate
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFractions(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		input    string
+		expected string
+		fraction string
+		reason   string
+	}{
+		{name: "not enabled", input: "2/3", expected: "0", reason: "not a number"},
+		{name: "fraction", opts: []Option{WithFractions()}, input: "2/3", expected: "0.6666666666666667", fraction: "2/3"},
+		{name: "reduced", opts: []Option{WithFractions()}, input: "4/2", expected: "2", fraction: "2"},
+		{name: "mixed number", opts: []Option{WithFractions()}, input: "1 1/2", expected: "1.5", fraction: "3/2"},
+		{name: "negative mixed number", opts: []Option{WithFractions()}, input: "-1 1/2", expected: "-1.5", fraction: "-3/2"},
+		{name: "plain number", opts: []Option{WithFractions()}, input: "0.5", expected: "0.5"},
+		{name: "zero denominator", opts: []Option{WithFractions()}, input: "1/0", expected: "0", reason: "fraction with a zero denominator"},
+		{name: "decimal numerator", opts: []Option{WithFractions()}, input: "1.5/2", expected: "0", reason: "not a number"},
+		{name: "parenthesized", opts: []Option{WithFractions(), WithFormats(Parentheses())}, input: "(1/4)", expected: "-0.25", fraction: "-1/4"},
+		{name: "percent", opts: []Option{WithFractions(), WithFormats(Percent())}, input: "1/2%", expected: "0.005", fraction: "1/200"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := New(append(test.opts, WithAllowNegatives(true), WithDelimiters("\n"))...)
+			parsed, err := v.Parse(test.input)
+			assert.NoError(t, err)
+
+			var streamed []Value
+			_, err = v.ParseStream(strings.NewReader(test.input), func(value Value) error {
+				streamed = append(streamed, value)
+				return nil
+			})
+			assert.NoError(t, err)
+
+			assert.Equal(t, test.expected, parsed.Values[0].String())
+			assert.Equal(t, test.expected, streamed[0].Value.String())
+			if test.fraction == "" {
+				assert.Nil(t, streamed[0].Fraction)
+				if parsed.Fractions != nil {
+					assert.Nil(t, parsed.Fractions[0])
+				}
+			} else {
+				assert.Equal(t, test.fraction, parsed.Fractions[0].RatString())
+				assert.Equal(t, test.fraction, streamed[0].Fraction.RatString())
+			}
+			if test.reason == "" {
+				assert.Empty(t, parsed.InvalidTokens)
+				assert.Nil(t, streamed[0].Invalid)
+			} else if assert.Len(t, parsed.InvalidTokens, 1) {
+				assert.Equal(t, test.reason, parsed.InvalidTokens[0].Reason)
+				assert.Equal(t, test.reason, streamed[0].Invalid.Reason)
+			}
+		})
+	}
+}
+
+func TestFractionsWorkers(t *testing.T) {
+	input := strings.Repeat("1/3,", 3*minParallelTokens) + "1 2/3"
+	parsed, err := New(WithFractions(), WithWorkers(4)).Parse(input)
+	assert.NoError(t, err)
+	assert.Len(t, parsed.Fractions, 3*minParallelTokens+1)
+	assert.Equal(t, big.NewRat(1, 3), parsed.Fractions[minParallelTokens])
+	assert.Equal(t, big.NewRat(5, 3), parsed.Fractions[3*minParallelTokens])
+}
+
+func TestFractionDelimiters(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		input       string
+		expectedErr string
+	}{
+		{name: "slash header", opts: []Option{WithFractions()}, input: "//[/]\n1/2", expectedErr: `ambiguous delimiter "/": it can be confused with fractions such as 2/3`},
+		{name: "space header", opts: []Option{WithFractions()}, input: "//[ ]\n1 2", expectedErr: `ambiguous delimiter " ": it can be confused with mixed numbers such as 1 1/2`},
+		{name: "slash delimiter", opts: []Option{WithFractions(), WithDelimiters("/")}, input: "1", expectedErr: `ambiguous delimiter "/": it can be confused with fractions such as 2/3`},
+		{name: "slash without fractions", input: "//[/]\n1/2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := New(test.opts...)
+			_, err := v.Parse(test.input)
+			_, streamErr := parseStreamAll(v, test.input)
+			if test.expectedErr == "" {
+				assert.NoError(t, err)
+				assert.NoError(t, streamErr)
+				return
+			}
+			assert.EqualError(t, err, test.expectedErr)
+			assert.EqualError(t, streamErr, test.expectedErr)
+		})
+	}
+}
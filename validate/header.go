@@ -0,0 +1,149 @@
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// processCustomDelimiters reads an optional custom delimiter header and
+// returns the rest of the input, the delimiters it declares and the byte
+// offset of each delimiter in the input. The header grammar is:
+//
+//	header    = "//" ( char | bracketed { bracketed } ) [ "\r" ] "\n"
+//	bracketed = "[" { char | escape } "]"
+//	escape    = "\" ( "]" | "[" | "\" | "t" | "n" | "r" | "u" hex hex hex hex )
+//
+// Inside brackets every character other than "]" and "\" is taken
+// literally, including a newline, so only the newline after the last bracket
+// ends the header. Empty brackets declare nothing. Input without a newline has
+// no header.
+func processCustomDelimiters(input string) (string, []string, []int, error) {
+	if !strings.HasPrefix(input, "//") || !strings.Contains(input, "\n") {
+		return input, nil, nil, nil
+	}
+
+	if !strings.HasPrefix(input[2:], "[") {
+		delimiterEnd := strings.Index(input, "\n")
+		delimiterDef := input[2:delimiterEnd]
+		if len(delimiterDef) > 1 {
+			// "//\r\n" declares "\r"; otherwise the "\r" ends the header.
+			delimiterDef = strings.TrimSuffix(delimiterDef, "\r")
+		}
+		if utf8.RuneCountInString(delimiterDef) != 1 {
+			return input, nil, nil, &DelimiterSyntaxError{Offset: 2, Delimiter: delimiterDef, Reason: "expected a single character"}
+		}
+		return input[delimiterEnd+1:], []string{delimiterDef}, []int{2}, nil
+	}
+
+	var customDelimiters []string
+	var offsets []int
+	pos := 2
+	for {
+		switch {
+		case strings.HasPrefix(input[pos:], "\n"):
+			return input[pos+1:], customDelimiters, offsets, nil
+		case strings.HasPrefix(input[pos:], "\r\n"):
+			return input[pos+2:], customDelimiters, offsets, nil
+		case pos == len(input) || input[pos] != '[':
+			return input, nil, nil, &DelimiterSyntaxError{Offset: pos, Reason: `expected "[" or the end of the header`}
+		}
+
+		delimiter, end, err := readBracketed(input, pos)
+		if err != nil {
+			return input, nil, nil, err
+		}
+		if delimiter != "" {
+			customDelimiters = append(customDelimiters, delimiter)
+			offsets = append(offsets, pos+1)
+		}
+		pos = end
+	}
+}
+
+// readBracketed reads the bracketed delimiter starting at the "[" at open.
+// It returns the delimiter with escapes decoded and the offset just past the
+// closing bracket.
+func readBracketed(input string, open int) (string, int, error) {
+	var sb strings.Builder
+	pos := open + 1
+	for pos < len(input) {
+		switch input[pos] {
+		case ']':
+			return sb.String(), pos + 1, nil
+		case '\\':
+			r, size, err := readEscape(input, pos)
+			if err != nil {
+				return "", 0, err
+			}
+			sb.WriteRune(r)
+			pos += size
+		default:
+			sb.WriteByte(input[pos])
+			pos++
+		}
+	}
+	return "", 0, &DelimiterSyntaxError{Offset: open, Reason: "missing closing bracket"}
+}
+
+// readEscape decodes the escape sequence starting at the backslash at pos and
+// returns the rune and the length of the sequence.
+func readEscape(input string, pos int) (rune, int, error) {
+	if pos+1 == len(input) {
+		return 0, 0, &DelimiterSyntaxError{Offset: pos, Reason: "unterminated escape sequence"}
+	}
+
+	switch input[pos+1] {
+	case ']', '[', '\\':
+		return rune(input[pos+1]), 2, nil
+	case 't':
+		return '\t', 2, nil
+	case 'n':
+		return '\n', 2, nil
+	case 'r':
+		return '\r', 2, nil
+	case 'u':
+		if pos+6 > len(input) {
+			return 0, 0, &DelimiterSyntaxError{Offset: pos, Reason: `\u must be followed by four hex digits`}
+		}
+		code, err := strconv.ParseUint(input[pos+2:pos+6], 16, 32)
+		if err != nil {
+			return 0, 0, &DelimiterSyntaxError{Offset: pos, Reason: `\u must be followed by four hex digits`}
+		}
+		if !utf8.ValidRune(rune(code)) {
+			return 0, 0, &DelimiterSyntaxError{Offset: pos, Reason: fmt.Sprintf("invalid code point U+%04X", code)}
+		}
+		return rune(code), 6, nil
+	}
+
+	_, size := utf8.DecodeRuneInString(input[pos+1:])
+	return 0, 0, &DelimiterSyntaxError{Offset: pos, Reason: fmt.Sprintf("unknown escape sequence %s", input[pos:pos+1+size])}
+}
+
+// regexHeaderPrefix starts a header whose delimiter is a regular expression.
+const regexHeaderPrefix = "//re:"
+
+// processRegexHeader reads an optional "//re:PATTERN\n" header and returns
+// the rest of the input and the compiled pattern. The pattern runs to the
+// end of the line and uses Go regexp syntax, without escapes of its own.
+func processRegexHeader(input string) (string, *regexp.Regexp, error) {
+	if !strings.HasPrefix(input, regexHeaderPrefix) {
+		return input, nil, nil
+	}
+	headerEnd := strings.Index(input, "\n")
+	if headerEnd == -1 {
+		return input, nil, nil
+	}
+
+	source := strings.TrimSuffix(input[len(regexHeaderPrefix):headerEnd], "\r")
+	if source == "" {
+		return input, nil, &DelimiterSyntaxError{Offset: len(regexHeaderPrefix), Reason: "empty pattern"}
+	}
+	pattern, err := regexp.Compile(source)
+	if err != nil {
+		return input, nil, &DelimiterSyntaxError{Offset: len(regexHeaderPrefix), Reason: fmt.Sprintf("invalid pattern: %v", err)}
+	}
+	return input[headerEnd+1:], pattern, nil
+}
@@ -0,0 +1,229 @@
+package validate
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeaderEscapes(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           string
+		expectedInput   string
+		expectedDelims  []string
+		expectedOffsets []int
+	}{
+		{
+			name:            "escaped closing bracket",
+			input:           `//[a\]b]` + "\n1a]b2",
+			expectedInput:   "1a]b2",
+			expectedDelims:  []string{"a]b"},
+			expectedOffsets: []int{3},
+		},
+		{
+			name:            "escaped opening bracket and backslash",
+			input:           `//[\[\\]` + "\n1[\\2",
+			expectedInput:   "1[\\2",
+			expectedDelims:  []string{"[\\"},
+			expectedOffsets: []int{3},
+		},
+		{
+			name:            "tab and section sign",
+			input:           `//[\t][§]` + "\n1\t2§3",
+			expectedInput:   "1\t2§3",
+			expectedDelims:  []string{"\t", "§"},
+			expectedOffsets: []int{3, 7},
+		},
+		{
+			name:            "escaped newline and carriage return",
+			input:           `//[\r\n]` + "\n1\r\n2",
+			expectedInput:   "1\r\n2",
+			expectedDelims:  []string{"\r\n"},
+			expectedOffsets: []int{3},
+		},
+		{
+			name:            "literal newline inside brackets",
+			input:           "//[\n]\n1\n2",
+			expectedInput:   "1\n2",
+			expectedDelims:  []string{"\n"},
+			expectedOffsets: []int{3},
+		},
+		{
+			name:            "bracketed header ending in CRLF",
+			input:           "//[;]\r\n1;2",
+			expectedInput:   "1;2",
+			expectedDelims:  []string{";"},
+			expectedOffsets: []int{3},
+		},
+		{
+			name:            "single character header ending in CRLF",
+			input:           "//;\r\n1;2",
+			expectedInput:   "1;2",
+			expectedDelims:  []string{";"},
+			expectedOffsets: []int{2},
+		},
+		{
+			name:            "carriage return as the single character",
+			input:           "//\r\n1\r2",
+			expectedInput:   "1\r2",
+			expectedDelims:  []string{"\r"},
+			expectedOffsets: []int{2},
+		},
+		{
+			name:            "single multi-byte character",
+			input:           "//§\n1§2",
+			expectedInput:   "1§2",
+			expectedDelims:  []string{"§"},
+			expectedOffsets: []int{2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rest, delimiters, offsets, err := processCustomDelimiters(test.input)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedInput, rest)
+			assert.Equal(t, test.expectedDelims, delimiters)
+			assert.Equal(t, test.expectedOffsets, offsets)
+		})
+	}
+}
+
+func TestHeaderErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedErr    string
+		expectedOffset int
+	}{
+		{
+			name:           "unknown escape",
+			input:          `//[;][\q]` + "\n1;2",
+			expectedErr:    `invalid delimiter format: unknown escape sequence \q`,
+			expectedOffset: 6,
+		},
+		{
+			name:           "short unicode escape",
+			input:          `//[\u00a]` + "\n1;2",
+			expectedErr:    `invalid delimiter format: \u must be followed by four hex digits`,
+			expectedOffset: 3,
+		},
+		{
+			name:           "surrogate code point",
+			input:          `//[\ud800]` + "\n1;2",
+			expectedErr:    "invalid delimiter format: invalid code point U+D800",
+			expectedOffset: 3,
+		},
+		{
+			name:           "text between brackets",
+			input:          "//[;]x[,]\n1;2",
+			expectedErr:    `invalid delimiter format: expected "[" or the end of the header`,
+			expectedOffset: 5,
+		},
+		{
+			name:           "escaped bracket never closed",
+			input:          `//[;\]` + "\n1;2",
+			expectedErr:    "invalid delimiter format: missing closing bracket",
+			expectedOffset: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := New().ValidateInput(test.input)
+			assert.EqualError(t, err, test.expectedErr)
+			assert.True(t, errors.Is(err, ErrDelimiterSyntax))
+
+			var syntaxErr *DelimiterSyntaxError
+			assert.True(t, errors.As(err, &syntaxErr))
+			assert.Equal(t, test.expectedOffset, syntaxErr.Offset)
+		})
+	}
+}
+
+func TestHeaderEscapesEndToEnd(t *testing.T) {
+	values, err := New().ValidateInput(`//[\t][§][a\]]` + "\n1\t2§3a]4")
+	assert.NoError(t, err)
+	assert.Len(t, values, 4)
+}
+
+func TestRegexHeader(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		input       string
+		expected    []int64
+		expectedErr string
+	}{
+		{
+			name:     "runs of separators",
+			input:    "//re:[;|\\s]+\n1;;2 | 3\t4",
+			expected: []int64{1, 2, 3, 4},
+		},
+		{
+			name:     "empty fields become zero",
+			input:    "//re:[;|]\n1;;2",
+			expected: []int64{1, 0, 2},
+		},
+		{
+			name:     "literal delimiters still apply",
+			input:    "//re:x+\n1xx2,3",
+			expected: []int64{1, 2, 3},
+		},
+		{
+			name:     "longest match wins over literal delimiters",
+			input:    "//re:,;+\n1,;;2,3",
+			expected: []int64{1, 2, 3},
+		},
+		{
+			name:        "strict mode rejects empty fields",
+			opts:        []Option{WithStrict(true)},
+			input:       "//re:;\n1;;2",
+			expectedErr: `invalid input: invalid tokens found: "" at index 1 (offset 9): missing number`,
+		},
+		{
+			name:        "invalid pattern",
+			input:       "//re:[;\n1;2",
+			expectedErr: "invalid delimiter format: invalid pattern: error parsing regexp: missing closing ]: `[;`",
+		},
+		{
+			name:        "empty pattern",
+			input:       "//re:\n1;2",
+			expectedErr: "invalid delimiter format: empty pattern",
+		},
+		{
+			name:        "pattern matching a minus sign",
+			input:       "//re:[-;]\n1;2",
+			expectedErr: `ambiguous delimiter "[-;]": it can be confused with negative numbers such as -5`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			values, err := New(test.opts...).ValidateInput(test.input)
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			expected := make([]decimal.Decimal, len(test.expected))
+			for i, value := range test.expected {
+				expected[i] = decimal.NewFromInt(value)
+			}
+			assert.Equal(t, expected, values)
+		})
+	}
+}
+
+func TestDelimiterPatternOption(t *testing.T) {
+	values, err := New(WithDelimiterPattern(regexp.MustCompile(`\s+`))).ValidateInput("1  2\t3,4")
+	assert.NoError(t, err)
+	assert.Len(t, values, 4)
+
+	_, err = New(WithDelimiterPattern(regexp.MustCompile(`[.]`))).CheckDelimiters()
+	assert.True(t, errors.Is(err, ErrAmbiguousDelimiter))
+}
@@ -0,0 +1,153 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidLocale is returned for a Locale that makes numbers ambiguous.
+var ErrInvalidLocale = errors.New("invalid locale")
+
+var (
+	errInvalidGrouping = errors.New("invalid digit grouping")
+	errUnclosedQuote   = errors.New("unclosed quote")
+)
+
+// Locale describes how numbers are written. Its Delimiter takes the place
+// of "," among the delimiters.
+//
+// A number may be wrapped in double quotes, and delimiters inside the quotes
+// do not split it. This is how a grouping separator that is also the
+// delimiter, as "," is for en-US, is written: "1,234.56".
+type Locale struct {
+	// DecimalMark separates the integer part of a number from its fraction.
+	DecimalMark string
+	// GroupSeparator separates groups of three digits in the integer part.
+	// It is optional, and empty when grouping is not accepted.
+	GroupSeparator string
+	// Delimiter separates values.
+	Delimiter string
+}
+
+var locales = map[string]Locale{
+	"en-us": {DecimalMark: ".", GroupSeparator: ",", Delimiter: ","},
+	"de-de": {DecimalMark: ",", GroupSeparator: ".", Delimiter: ";"},
+	"fr-fr": {DecimalMark: ",", GroupSeparator: " ", Delimiter: ";"},
+	"de-ch": {DecimalMark: ".", GroupSeparator: "'", Delimiter: ","},
+}
+
+// LookupLocale returns the Locale with the given name, such as "de-DE".
+func LookupLocale(name string) (Locale, error) {
+	key := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "_", "-"))
+	locale, ok := locales[key]
+	if !ok {
+		return Locale{}, fmt.Errorf("unknown locale: %q", name)
+	}
+	return locale, nil
+}
+
+// LocaleNames lists the names accepted by LookupLocale.
+func LocaleNames() []string {
+	names := make([]string, 0, len(locales))
+	for key := range locales {
+		language, region, _ := strings.Cut(key, "-")
+		names = append(names, language+"-"+strings.ToUpper(region))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithLocale parses numbers written for locale.
+func WithLocale(locale Locale) Option {
+	return func(v *Validator) {
+		v.locale = &locale
+	}
+}
+
+// Validate reports a Locale whose parts can be confused with each other or
+// with the rest of a number.
+func (l Locale) Validate() error {
+	parts := []struct {
+		name  string
+		value string
+	}{{"decimal mark", l.DecimalMark}, {"group separator", l.GroupSeparator}, {"delimiter", l.Delimiter}}
+	for _, part := range parts {
+		if part.value == "" {
+			if part.name == "group separator" {
+				continue
+			}
+			return fmt.Errorf("%w: the %s is empty", ErrInvalidLocale, part.name)
+		}
+		if strings.ContainsAny(part.value, `0123456789+-eE"`) {
+			return fmt.Errorf("%w: the %s %q contains a digit, sign, exponent or quote", ErrInvalidLocale, part.name, part.value)
+		}
+	}
+	if l.Delimiter == l.DecimalMark {
+		return fmt.Errorf("%w: the delimiter and the decimal mark are both %q", ErrInvalidLocale, l.Delimiter)
+	}
+	if l.GroupSeparator == l.DecimalMark {
+		return fmt.Errorf("%w: the group separator and the decimal mark are both %q", ErrInvalidLocale, l.DecimalMark)
+	}
+	if strings.Contains(l.Delimiter, l.DecimalMark) || strings.Contains(l.DecimalMark, l.Delimiter) {
+		return fmt.Errorf("%w: the delimiter %q and the decimal mark %q overlap", ErrInvalidLocale, l.Delimiter, l.DecimalMark)
+	}
+	return nil
+}
+
+// unquote removes the double quotes around text, if there are any.
+func unquote(text string) (string, error) {
+	if !strings.HasPrefix(text, `"`) {
+		return text, nil
+	}
+	if len(text) < 2 || !strings.HasSuffix(text, `"`) {
+		return "", errUnclosedQuote
+	}
+	return strings.TrimSpace(text[1 : len(text)-1]), nil
+}
+
+// parse converts a number written for the locale, reporting why it is not a
+// number.
+func (l *Locale) parse(text string) (decimal.Decimal, error) {
+	if l.GroupSeparator != "" && strings.Contains(text, l.GroupSeparator) {
+		var ok bool
+		if text, ok = l.ungroup(text); !ok {
+			return decimal.Zero, errInvalidGrouping
+		}
+	}
+	if l.DecimalMark != "." {
+		// "." is not a decimal point here, so 1.5 is not one and a half.
+		if strings.Contains(text, ".") {
+			return decimal.Zero, errNotANumber
+		}
+		text = strings.Replace(text, l.DecimalMark, ".", 1)
+	}
+	return parseNumber(text)
+}
+
+// ungroup removes the group separators from the integer part of text, which
+// must split it into groups of three digits after the first.
+func (l *Locale) ungroup(text string) (string, bool) {
+	sign := ""
+	if text != "" && (text[0] == '+' || text[0] == '-') {
+		sign, text = text[:1], text[1:]
+	}
+	integer, fraction := text, ""
+	if i := strings.Index(text, l.DecimalMark); i != -1 {
+		integer, fraction = text[:i], text[i:]
+	}
+	if strings.Contains(fraction, l.GroupSeparator) {
+		return "", false
+	}
+
+	groups := strings.Split(integer, l.GroupSeparator)
+	for i, group := range groups {
+		if group == "" || len(group) > 3 || (i > 0 && len(group) != 3) || strings.Trim(group, "0123456789") != "" {
+			return "", false
+		}
+	}
+	return sign + strings.Join(groups, "") + fraction, true
+}
@@ -0,0 +1,172 @@
+package validate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupLocale(t *testing.T) {
+	for _, name := range LocaleNames() {
+		locale, err := LookupLocale(name)
+		assert.NoError(t, err, name)
+		assert.NoError(t, locale.Validate(), name)
+	}
+
+	locale, err := LookupLocale(" de_de ")
+	assert.NoError(t, err)
+	assert.Equal(t, Locale{DecimalMark: ",", GroupSeparator: ".", Delimiter: ";"}, locale)
+
+	_, err = LookupLocale("xx-XX")
+	assert.EqualError(t, err, `unknown locale: "xx-XX"`)
+}
+
+func TestLocaleValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		locale      Locale
+		expectedErr string
+	}{
+		{name: "no grouping", locale: Locale{DecimalMark: ",", Delimiter: ";"}},
+		{name: "delimiter is the decimal mark", locale: Locale{DecimalMark: ",", Delimiter: ","}, expectedErr: `invalid locale: the delimiter and the decimal mark are both ","`},
+		{name: "group separator is the decimal mark", locale: Locale{DecimalMark: ".", GroupSeparator: ".", Delimiter: ","}, expectedErr: `invalid locale: the group separator and the decimal mark are both "."`},
+		{name: "delimiter contains the decimal mark", locale: Locale{DecimalMark: ",", Delimiter: ",;"}, expectedErr: `invalid locale: the delimiter ",;" and the decimal mark "," overlap`},
+		{name: "empty decimal mark", locale: Locale{Delimiter: ","}, expectedErr: "invalid locale: the decimal mark is empty"},
+		{name: "empty delimiter", locale: Locale{DecimalMark: "."}, expectedErr: "invalid locale: the delimiter is empty"},
+		{name: "digit", locale: Locale{DecimalMark: ".", Delimiter: "0"}, expectedErr: `invalid locale: the delimiter "0" contains a digit, sign, exponent or quote`},
+		{name: "quote", locale: Locale{DecimalMark: ".", GroupSeparator: `"`, Delimiter: ","}, expectedErr: `invalid locale: the group separator "\"" contains a digit, sign, exponent or quote`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.locale.Validate()
+			if test.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, test.expectedErr)
+			assert.True(t, errors.Is(err, ErrInvalidLocale))
+
+			_, err = New(WithLocale(test.locale)).Parse("1")
+			assert.True(t, errors.Is(err, ErrInvalidLocale))
+			_, err = parseStreamAll(New(WithLocale(test.locale)), "1")
+			assert.True(t, errors.Is(err, ErrInvalidLocale))
+		})
+	}
+}
+
+func TestLocaleParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		locale   string
+		input    string
+		expected []string
+		invalid  map[int]string
+	}{
+		{name: "en-US quoted grouping", locale: "en-US", input: `"1,234.56",7`, expected: []string{"1234.56", "7"}},
+		{name: "en-US unquoted comma splits", locale: "en-US", input: "1,234.56", expected: []string{"1", "234.56"}},
+		{name: "en-US quoted plain number", locale: "en-US", input: `" 5 ",6`, expected: []string{"5", "6"}},
+		{name: "en-US newline inside quotes", locale: "en-US", input: "\"1,\n234\"\n2", expected: []string{"0", "2"}, invalid: map[int]string{0: "invalid digit grouping"}},
+		{name: "de-DE", locale: "de-DE", input: "1.234,56;7\n0,5", expected: []string{"1234.56", "7", "0.5"}},
+		{name: "de-DE comma is not a delimiter", locale: "de-DE", input: "1,5", expected: []string{"1.5"}},
+		{name: "de-DE point is not a decimal mark", locale: "de-DE", input: "1.5;2", expected: []string{"0", "2"}, invalid: map[int]string{0: "invalid digit grouping"}},
+		{name: "de-DE negative", locale: "de-DE", input: "-1.000,5", expected: []string{"-1000.5"}},
+		{name: "fr-FR", locale: "fr-FR", input: "1 234,5;2", expected: []string{"1234.5", "2"}},
+		{name: "fr-FR point is not a number", locale: "fr-FR", input: "1.5", expected: []string{"0"}, invalid: map[int]string{0: "not a number"}},
+		{name: "de-CH", locale: "de-CH", input: "1'234.5,2", expected: []string{"1234.5", "2"}},
+		{name: "short group", locale: "de-CH", input: "1'23", expected: []string{"0"}, invalid: map[int]string{0: "invalid digit grouping"}},
+		{name: "long first group", locale: "de-CH", input: "1234'567", expected: []string{"0"}, invalid: map[int]string{0: "invalid digit grouping"}},
+		{name: "grouped fraction", locale: "de-CH", input: "1.234'5", expected: []string{"0"}, invalid: map[int]string{0: "invalid digit grouping"}},
+		{name: "unclosed quote", locale: "en-US", input: `"1,2`, expected: []string{"0"}, invalid: map[int]string{0: "unclosed quote"}},
+		{name: "empty quotes", locale: "en-US", input: `"",1`, expected: []string{"0", "1"}, invalid: map[int]string{0: "missing number"}},
+		{name: "custom header", locale: "de-DE", input: "//|\n1,5|2", expected: []string{"1.5", "2"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			locale, err := LookupLocale(test.locale)
+			assert.NoError(t, err)
+			v := New(WithLocale(locale), WithAllowNegatives(true))
+
+			parsed, err := v.Parse(test.input)
+			assert.NoError(t, err)
+			streamed, err := parseStreamAll(v, test.input)
+			assert.NoError(t, err)
+
+			for _, p := range []*Parsed{parsed, streamed} {
+				values := make([]string, len(p.Values))
+				for i, value := range p.Values {
+					values[i] = value.String()
+				}
+				assert.Equal(t, test.expected, values)
+
+				invalid := map[int]string{}
+				for _, token := range p.InvalidTokens {
+					invalid[token.Index] = token.Reason
+				}
+				if test.invalid == nil {
+					assert.Empty(t, invalid)
+				} else {
+					assert.Equal(t, test.invalid, invalid)
+				}
+			}
+			assert.Equal(t, parsed.Offsets, streamed.Offsets)
+		})
+	}
+}
+
+func TestLocaleDelimiters(t *testing.T) {
+	deDE, _ := LookupLocale("de-DE")
+	enUS, _ := LookupLocale("en-US")
+
+	tests := []struct {
+		name        string
+		locale      Locale
+		opts        []Option
+		input       string
+		expectedErr string
+	}{
+		{name: "comma header with comma decimals", locale: deDE, input: "//,\n1;2", expectedErr: `ambiguous delimiter ",": it can be confused with decimals such as 1,5 or ,5`},
+		{name: "point header with point grouping", locale: deDE, input: "//.\n1;2", expectedErr: `ambiguous delimiter ".": it can be confused with grouped numbers such as 1.234`},
+		{name: "point header without a locale", locale: enUS, input: "//.\n1,2", expectedErr: `ambiguous delimiter ".": it can be confused with decimals such as 1.5 or .5`},
+		{name: "comma header when grouping is quoted", locale: enUS, input: "//,\n1,2"},
+		{name: "default comma is replaced", locale: deDE, opts: []Option{WithDefaultDelimiter("|")}, input: "1,5|2;3"},
+		{name: "configured comma", locale: deDE, opts: []Option{WithDelimiters(",", "|")}, input: "1,5|2", expectedErr: `ambiguous delimiter ",": it can be confused with decimals such as 1,5 or ,5`},
+		{name: "comma as the default delimiter", locale: deDE, opts: []Option{WithDefaultDelimiter(",")}, input: "1,5,2", expectedErr: `ambiguous delimiter ",": it can be confused with decimals such as 1,5 or ,5`},
+		{name: "configured point", locale: deDE, opts: []Option{WithDelimiters(".")}, input: "1", expectedErr: `ambiguous delimiter ".": it can be confused with grouped numbers such as 1.234`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := New(append(test.opts, WithLocale(test.locale))...)
+			_, err := v.Parse(test.input)
+			_, streamErr := parseStreamAll(v, test.input)
+			if test.expectedErr == "" {
+				assert.NoError(t, err)
+				assert.NoError(t, streamErr)
+				return
+			}
+			assert.EqualError(t, err, test.expectedErr)
+			assert.EqualError(t, streamErr, test.expectedErr)
+		})
+	}
+}
+
+func TestLocaleWorkers(t *testing.T) {
+	locale, _ := LookupLocale("de-DE")
+	input := strings.Repeat("1.234,5;", 3*minParallelTokens) + "1"
+
+	sequential, err := New(WithLocale(locale)).Parse(input)
+	assert.NoError(t, err)
+	parallel, err := New(WithLocale(locale), WithWorkers(4)).Parse(input)
+	assert.NoError(t, err)
+	streamed, err := parseStreamAll(New(WithLocale(locale), WithWorkers(4)), input)
+	assert.NoError(t, err)
+
+	assert.Equal(t, sequential.Values, parallel.Values)
+	assert.Equal(t, sequential.Values, streamed.Values)
+	assert.True(t, sequential.Values[0].Equal(decimal.RequireFromString("1234.5")))
+}
@@ -0,0 +1,53 @@
+package validate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NegativesPolicy is how negative numbers in the input are treated.
+type NegativesPolicy string
+
+const (
+	// NegativesError rejects input containing negative numbers.
+	NegativesError NegativesPolicy = "error"
+	// NegativesAllow uses negative numbers like any other.
+	NegativesAllow NegativesPolicy = "allow"
+	// NegativesIgnore leaves negative numbers out of calculations, as
+	// numbers above the maximum are.
+	NegativesIgnore NegativesPolicy = "ignore"
+	// NegativesAbs uses the absolute value of negative numbers.
+	NegativesAbs NegativesPolicy = "abs"
+	// NegativesErrorTotal allows negative numbers but rejects a negative
+	// result.
+	NegativesErrorTotal NegativesPolicy = "error-total"
+)
+
+// ParseNegativesPolicy accepts the name of a NegativesPolicy.
+func ParseNegativesPolicy(name string) (NegativesPolicy, error) {
+	policy := NegativesPolicy(strings.ToLower(strings.TrimSpace(name)))
+	switch policy {
+	case NegativesError, NegativesAllow, NegativesIgnore, NegativesAbs, NegativesErrorTotal:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown negatives policy: %q", name)
+}
+
+// WithNegatives sets how negative numbers are treated. Only NegativesError
+// is enforced by the Validator; the other policies are applied by whatever
+// uses the parsed values, which can read the policy with Negatives.
+func WithNegatives(policy NegativesPolicy) Option {
+	return func(v *Validator) {
+		v.negatives = policy
+	}
+}
+
+// Negatives returns the policy for negative numbers.
+func (v *Validator) Negatives() NegativesPolicy {
+	return v.negatives
+}
+
+// SetNegatives sets the negatives policy used by the free functions.
+func SetNegatives(policy NegativesPolicy) {
+	negatives = policy
+}
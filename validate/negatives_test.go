@@ -0,0 +1,55 @@
+package validate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNegativesPolicy(t *testing.T) {
+	for _, name := range []string{"error", "allow", "ignore", "abs", "error-total", " ABS "} {
+		_, err := ParseNegativesPolicy(name)
+		assert.NoError(t, err, name)
+	}
+	_, err := ParseNegativesPolicy("sometimes")
+	assert.EqualError(t, err, `unknown negatives policy: "sometimes"`)
+}
+
+func TestNegativesPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected NegativesPolicy
+		rejected bool
+	}{
+		{name: "default", expected: NegativesError, rejected: true},
+		{name: "allow", opts: []Option{WithAllowNegatives(true)}, expected: NegativesAllow},
+		{name: "disallow", opts: []Option{WithAllowNegatives(false)}, expected: NegativesError, rejected: true},
+		{name: "ignore", opts: []Option{WithNegatives(NegativesIgnore)}, expected: NegativesIgnore},
+		{name: "abs", opts: []Option{WithNegatives(NegativesAbs)}, expected: NegativesAbs},
+		{name: "error total", opts: []Option{WithNegatives(NegativesErrorTotal)}, expected: NegativesErrorTotal},
+		{name: "last option wins", opts: []Option{WithNegatives(NegativesIgnore), WithAllowNegatives(true)}, expected: NegativesAllow},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := New(test.opts...)
+			assert.Equal(t, test.expected, v.Negatives())
+
+			_, err := v.Parse("1,-2")
+			_, streamErr := parseStreamAll(v, "1,-2")
+			assert.Equal(t, test.rejected, errors.Is(err, ErrNegativeNumbers))
+			assert.Equal(t, test.rejected, errors.Is(streamErr, ErrNegativeNumbers))
+		})
+	}
+}
+
+func TestSetNegatives(t *testing.T) {
+	defer SetNegatives(NegativesError)
+
+	SetNegatives(NegativesAbs)
+	assert.Equal(t, NegativesAbs, Default().Negatives())
+	SetAllowNegatives(false)
+	assert.Equal(t, NegativesError, Default().Negatives())
+}
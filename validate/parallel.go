@@ -0,0 +1,207 @@
+package validate
+
+import (
+	"math/big"
+	"sync"
+
+	"challenge-calculator/internal/chunk"
+	"challenge-calculator/logger"
+
+	"github.com/shopspring/decimal"
+)
+
+// minParallelTokens is the smallest number of tokens worth handing to a
+// worker of its own.
+const minParallelTokens = 4096
+
+// WithWorkers parses large inputs on up to n goroutines. The values, and
+// their order, are the same as with a single one. Splitting the input into
+// tokens is still done in one pass before the work is shared out.
+func WithWorkers(n int) Option {
+	return func(v *Validator) {
+		v.workers = n
+	}
+}
+
+// parsedNumber is the outcome of parsing a token: its value, the currency it
+// was written in, if any, and the exact fraction it was written as, if any.
+type parsedNumber struct {
+	value    decimal.Decimal
+	currency string
+	fraction *big.Rat
+}
+
+// parseFunc converts the text of a token to a number.
+type parseFunc func(string) (parsedNumber, error)
+
+// parseTokens converts tokens to the values, offsets and invalid tokens of
+// parsed, splitting the work between up to workers goroutines, each
+// converting tokens with parse. Everything is filled in in input order.
+// Currencies and Fractions are only filled in if they are as long as tokens.
+func parseTokens(tokens []Token, workers int, parse parseFunc, parsed *Parsed) {
+	parsed.Values = make([]decimal.Decimal, len(tokens))
+	parsed.Offsets = make([]int, len(tokens))
+
+	ranges := chunk.Ranges(len(tokens), workers, minParallelTokens)
+	invalid := make([][]*InvalidTokenError, len(ranges))
+	var wg sync.WaitGroup
+	for i, r := range ranges {
+		wg.Add(1)
+		go func(i, start, end int) {
+			defer wg.Done()
+			invalid[i] = parseTokenRange(tokens[start:end], parsed.slice(start, end), parse)
+		}(i, r[0], r[1])
+	}
+	wg.Wait()
+
+	for _, chunk := range invalid {
+		parsed.InvalidTokens = append(parsed.InvalidTokens, chunk...)
+	}
+}
+
+// slice returns the values of p from start to end, sharing their storage.
+func (p *Parsed) slice(start, end int) *Parsed {
+	s := &Parsed{Values: p.Values[start:end], Offsets: p.Offsets[start:end]}
+	if p.Currencies != nil {
+		s.Currencies = p.Currencies[start:end]
+	}
+	if p.Fractions != nil {
+		s.Fractions = p.Fractions[start:end]
+	}
+	return s
+}
+
+// set stores number as the value at index i.
+func (p *Parsed) set(i int, number parsedNumber, offset int) {
+	p.Values[i] = number.value
+	p.Offsets[i] = offset
+	if p.Currencies != nil {
+		p.Currencies[i] = number.currency
+	}
+	if p.Fractions != nil {
+		p.Fractions[i] = number.fraction
+	}
+}
+
+// parseTokenRange parses tokens into parsed, whose values have the same
+// length.
+func parseTokenRange(tokens []Token, parsed *Parsed, parse parseFunc) []*InvalidTokenError {
+	var invalidTokens []*InvalidTokenError
+	for i, token := range tokens {
+		number, err := parse(token.Text)
+		if err == errMissingNumber {
+			// Missing numbers read as "0", as they always have.
+			number.value = decimal.NewFromInt(0)
+		}
+		if err != nil {
+			logger.DebugFields("Invalid number format, converting to 0", logger.Fields{"token": token.Text, "offset": token.Offset})
+			invalidTokens = append(invalidTokens, newInvalidTokenError(token.Text, token.Index, token.Offset, err))
+		}
+		parsed.set(i, number, token.Offset)
+	}
+	return invalidTokens
+}
+
+// streamBatchSize is the number of tokens a stream hands to a worker at once.
+const streamBatchSize = 4096
+
+// tokenBatch is a run of streamed tokens parsed by one worker. done is
+// closed once numbers and errs are filled in.
+type tokenBatch struct {
+	tokens  []pendingToken
+	numbers []parsedNumber
+	errs    []error
+	done    chan struct{}
+}
+
+// parsePool parses streamed tokens on several goroutines while handing them
+// to the splitter in order. At most twice as many batches as workers are in
+// flight, so memory use stays bounded.
+type parsePool struct {
+	splitter *streamSplitter
+	work     chan *tokenBatch
+	queue    []*tokenBatch
+	current  *tokenBatch
+	limit    int
+}
+
+func newParsePool(splitter *streamSplitter, workers int) *parsePool {
+	p := &parsePool{
+		splitter: splitter,
+		work:     make(chan *tokenBatch, workers),
+		limit:    2 * workers,
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for batch := range p.work {
+				for i, token := range batch.tokens {
+					batch.numbers[i], batch.errs[i] = token.parse(p.splitter.validator.parseToken)
+				}
+				close(batch.done)
+			}
+		}()
+	}
+	return p
+}
+
+func (p *parsePool) push(token pendingToken) error {
+	if p.current == nil {
+		p.current = &tokenBatch{tokens: make([]pendingToken, 0, streamBatchSize)}
+	}
+	p.current.tokens = append(p.current.tokens, token)
+	if len(p.current.tokens) < streamBatchSize {
+		return nil
+	}
+	return p.submit()
+}
+
+// submit hands the current batch to the workers, first delivering finished
+// batches if too many are in flight.
+func (p *parsePool) submit() error {
+	batch := p.current
+	p.current = nil
+	batch.numbers = make([]parsedNumber, len(batch.tokens))
+	batch.errs = make([]error, len(batch.tokens))
+	batch.done = make(chan struct{})
+	p.work <- batch
+	p.queue = append(p.queue, batch)
+
+	for len(p.queue) >= p.limit {
+		if err := p.deliverNext(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *parsePool) deliverNext() error {
+	batch := p.queue[0]
+	p.queue = p.queue[1:]
+	<-batch.done
+	for i, token := range batch.tokens {
+		if err := p.splitter.deliver(token, batch.numbers[i], batch.errs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finish delivers every remaining token.
+func (p *parsePool) finish() error {
+	if p.current != nil {
+		if err := p.submit(); err != nil {
+			return err
+		}
+	}
+	for len(p.queue) > 0 {
+		if err := p.deliverNext(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// close stops the workers once they finish the batches already submitted.
+func (p *parsePool) close() {
+	close(p.work)
+}
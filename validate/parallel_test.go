@@ -0,0 +1,79 @@
+package validate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// largeInput repeats a mix of valid, invalid, empty and oversized tokens
+// across enough terms to be split between workers.
+func largeInput(terms int) string {
+	parts := []string{"1", "2.5", "abc", "", "1001", " 7 ", "1e2", ".5"}
+	var sb strings.Builder
+	for i := 0; i < terms; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(parts[i%len(parts)])
+	}
+	return sb.String()
+}
+
+func TestParseWorkersMatchesSequential(t *testing.T) {
+	inputs := []string{
+		"",
+		"1,2,3",
+		largeInput(3 * minParallelTokens),
+		"//;\n" + strings.ReplaceAll(largeInput(5*minParallelTokens+3), ",", ";"),
+	}
+
+	for _, input := range inputs {
+		expected, err := New().Parse(input)
+		assert.NoError(t, err)
+		for _, workers := range []int{2, 4, 7} {
+			actual, err := New(WithWorkers(workers)).Parse(input)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, actual, "%d workers", workers)
+
+			streamed, err := parseStreamAll(New(WithWorkers(workers)), input)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, streamed, "%d workers, streamed", workers)
+		}
+	}
+}
+
+func TestParseWorkersErrors(t *testing.T) {
+	input := largeInput(3*minParallelTokens) + ",-1,-2"
+	expected, expectedErr := New().Parse(input)
+	assert.Nil(t, expected)
+
+	_, err := New(WithWorkers(4)).Parse(input)
+	assert.Equal(t, expectedErr, err)
+
+	// A stream stops at the first negative number, as without workers.
+	_, expectedErr = parseStreamAll(New(), input)
+	assert.EqualError(t, expectedErr, "invalid input: negative numbers found: -1")
+	_, err = parseStreamAll(New(WithWorkers(4)), input)
+	assert.Equal(t, expectedErr, err)
+
+	// Strict mode stops at the first invalid token, as without workers.
+	_, expectedErr = parseStreamAll(New(WithStrict(true)), input)
+	_, err = parseStreamAll(New(WithStrict(true), WithWorkers(4)), input)
+	assert.Equal(t, expectedErr, err)
+
+	// An error from visit stops the stream.
+	stop := errors.New("stop")
+	visited := 0
+	_, err = New(WithWorkers(4)).ParseStream(strings.NewReader(input), func(Value) error {
+		visited++
+		if visited == 10 {
+			return stop
+		}
+		return nil
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 10, visited)
+}
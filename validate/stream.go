@@ -0,0 +1,406 @@
+package validate
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/shopspring/decimal"
+)
+
+// streamBufferSize is the read buffer used by ParseStream. A custom delimiter
+// header has to fit in it.
+var streamBufferSize = 64 * 1024
+
+// maxStreamTokenLength bounds the text kept for one token while streaming.
+// Longer tokens are invalid.
+const maxStreamTokenLength = 1024
+
+// ErrStreamPattern is returned by ParseStream when regular-expression
+// delimiters are in use, since they cannot be matched incrementally.
+var ErrStreamPattern = errors.New("regular-expression delimiters are not supported for streamed input")
+
+var errTokenTooLong = errors.New("token too long")
+
+// Value is a single value read by ParseStream. Invalid is set, and Value is
+// zero, when the token was not a number. Currency is the ISO 4217 code the
+// value was written with, if any, and Fraction the exact value of a number
+// written as a fraction.
+type Value struct {
+	Index    int
+	Offset   int
+	Value    decimal.Decimal
+	Currency string
+	Fraction *big.Rat
+	Invalid  *InvalidTokenError
+}
+
+// ParseStream reads the same input as Parse from r and calls visit with each
+// value as soon as it is read, so memory use does not grow with the input.
+// It returns the delimiter warnings Parse would report.
+//
+// Errors that Parse reports before any value is returned can come after
+// some values have been visited: the first negative number stops the stream
+// and is the only one reported, and in strict mode so does the first invalid
+// token. Neither is visited.
+func (v *Validator) ParseStream(r io.Reader, visit func(Value) error) ([]string, error) {
+	if len(v.patterns) > 0 {
+		return nil, ErrStreamPattern
+	}
+
+	br := bufio.NewReaderSize(r, streamBufferSize)
+	headerLength, customDelimiters, customOffsets, err := readStreamHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	warnings, err := v.checkDelimiters(nil, customDelimiters, customOffsets, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	delimiters := append(customDelimiters, v.delimiters...)
+	s := &streamSplitter{
+		validator: v,
+		tokenizer: v.newTokenizer(delimiters, nil),
+		lookahead: utf8.UTFMax,
+		visit:     visit,
+		base:      headerLength,
+		pos:       headerLength,
+		wsStart:   -1,
+	}
+	for _, delimiter := range delimiters {
+		if len(delimiter) > s.lookahead {
+			s.lookahead = len(delimiter)
+		}
+	}
+	if s.lookahead >= br.Size() {
+		br = bufio.NewReaderSize(br, 2*s.lookahead)
+	}
+
+	if v.workers > 1 {
+		s.pool = newParsePool(s, v.workers)
+		defer s.pool.close()
+	}
+	if err := s.run(br); err != nil {
+		return nil, err
+	}
+	if s.pool != nil {
+		if err := s.pool.finish(); err != nil {
+			return nil, err
+		}
+	}
+	return warnings, nil
+}
+
+// readStreamHeader consumes a custom delimiter header from br, if there is
+// one, and returns its length along with the delimiters it declares.
+func readStreamHeader(br *bufio.Reader) (int, []string, []int, error) {
+	if prefix, _ := br.Peek(2); string(prefix) != "//" {
+		return 0, nil, nil, nil
+	}
+
+	window, err := br.Peek(br.Size())
+	if err != nil && err != io.EOF {
+		return 0, nil, nil, err
+	}
+	if strings.HasPrefix(string(window), regexHeaderPrefix) {
+		return 0, nil, nil, ErrStreamPattern
+	}
+
+	rest, customDelimiters, customOffsets, err := processCustomDelimiters(string(window))
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	headerLength := len(window) - len(rest)
+	if _, err := br.Discard(headerLength); err != nil {
+		return 0, nil, nil, err
+	}
+	return headerLength, customDelimiters, customOffsets, nil
+}
+
+// streamSplitter splits a stream into tokens the way tokenizer.split does
+// for a string. Since split trims the whole input first, empty tokens inside
+// trailing whitespace are held back until a later character shows they are
+// not trailing after all.
+type streamSplitter struct {
+	validator *Validator
+	tokenizer *tokenizer
+	lookahead int
+	visit     func(Value) error
+
+	// base is the offset of the first byte after the header, and pos the
+	// offset of the next unread byte.
+	base    int
+	pos     int
+	started bool
+	index   int
+
+	// The current token: its raw start, the text kept so far and whether
+	// some was discarded.
+	tokenStart int
+	token      []byte
+	overflow   bool
+	// quoted is set between the double quotes of a quoted token.
+	quoted bool
+
+	// wsStart is where the current run of whitespace began, or -1 if the
+	// last byte read was not whitespace.
+	wsStart int
+	// deferred holds the offsets of empty tokens that may be trailing.
+	deferred []int
+
+	// pool parses tokens when there are several workers.
+	pool *parsePool
+}
+
+func (s *streamSplitter) run(br *bufio.Reader) error {
+	for {
+		window, err := br.Peek(br.Size())
+		if err != nil && err != io.EOF {
+			return err
+		}
+		atEOF := err == io.EOF
+		chunk := string(window)
+
+		limit := len(chunk)
+		if !atEOF {
+			limit -= s.lookahead
+		}
+
+		i := 0
+		if !s.started {
+			i = skipSpace(chunk, limit)
+			if i < limit || atEOF {
+				s.started = true
+				s.tokenStart = s.pos + i
+			}
+			if atEOF && i == len(chunk) {
+				// Blank input is a single zero, as in Parse.
+				return s.visit(Value{Offset: s.base, Value: decimal.Zero})
+			}
+		}
+
+		for s.started && i < limit {
+			if s.tokenizer.quotes && chunk[i] == '"' {
+				s.quoted = !s.quoted
+			} else if length := s.matchAt(chunk, i); length > 0 {
+				if err := s.endToken(s.pos + i); err != nil {
+					return err
+				}
+				if strings.TrimSpace(chunk[i:i+length]) != "" {
+					s.wsStart = -1
+				} else if s.wsStart == -1 {
+					s.wsStart = s.pos + i
+				}
+				i += length
+				s.tokenStart = s.pos + i
+				continue
+			}
+
+			size, space := 1, false
+			if c := chunk[i]; c < utf8.RuneSelf {
+				space = asciiSpace[c]
+			} else {
+				var r rune
+				r, size = utf8.DecodeRuneInString(chunk[i:])
+				space = unicode.IsSpace(r)
+			}
+			if space {
+				if s.wsStart == -1 {
+					s.wsStart = s.pos + i
+				}
+			} else {
+				s.wsStart = -1
+				if err := s.flushDeferred(); err != nil {
+					return err
+				}
+			}
+			s.appendToken(chunk[i : i+size])
+			i += size
+		}
+
+		if _, err := br.Discard(i); err != nil {
+			return err
+		}
+		s.pos += i
+		if atEOF && i >= len(chunk) {
+			if s.trailing() && s.isEmptyToken() {
+				return nil
+			}
+			return s.endToken(s.pos)
+		}
+	}
+}
+
+// matchAt returns the length of the delimiter at i in chunk, or 0 inside
+// quotes.
+func (s *streamSplitter) matchAt(chunk string, i int) int {
+	if s.quoted {
+		return 0
+	}
+	return s.tokenizer.matchAt(chunk, i)
+}
+
+func skipSpace(chunk string, limit int) int {
+	i := 0
+	for i < limit {
+		if c := chunk[i]; c < utf8.RuneSelf {
+			if !asciiSpace[c] {
+				break
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(chunk[i:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+func (s *streamSplitter) appendToken(text string) {
+	if len(s.token)+len(text) > maxStreamTokenLength {
+		s.overflow = true
+		return
+	}
+	s.token = append(s.token, text...)
+}
+
+// trailing reports whether the current token follows a whitespace delimiter
+// within a run of whitespace, so it is trailing if the input ends here.
+func (s *streamSplitter) trailing() bool {
+	return s.wsStart != -1 && s.wsStart < s.tokenStart
+}
+
+func (s *streamSplitter) isEmptyToken() bool {
+	return !s.overflow && strings.TrimSpace(string(s.token)) == ""
+}
+
+// endToken finishes the current token, which ends at end.
+func (s *streamSplitter) endToken(end int) error {
+	defer func() {
+		s.token = s.token[:0]
+		s.overflow = false
+	}()
+
+	if s.isEmptyToken() {
+		if s.trailing() {
+			s.deferred = append(s.deferred, end)
+			return nil
+		}
+		if err := s.flushDeferred(); err != nil {
+			return err
+		}
+		return s.push(pendingToken{offset: end, err: errMissingNumber})
+	}
+
+	if err := s.flushDeferred(); err != nil {
+		return err
+	}
+	raw := string(s.token)
+	start, stop := trimSpan(raw, 0, len(raw))
+	if s.overflow {
+		return s.push(pendingToken{text: raw[start:stop] + "...", offset: s.tokenStart + start, err: errTokenTooLong})
+	}
+	return s.push(pendingToken{text: raw[start:stop], offset: s.tokenStart + start})
+}
+
+func (s *streamSplitter) flushDeferred() error {
+	deferred := s.deferred
+	s.deferred = nil
+	for _, offset := range deferred {
+		if err := s.push(pendingToken{offset: offset, err: errMissingNumber}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// push parses a token, or queues it for the workers if there are any.
+func (s *streamSplitter) push(token pendingToken) error {
+	if s.pool != nil {
+		return s.pool.push(token)
+	}
+	number, err := token.parse(s.validator.parseToken)
+	return s.deliver(token, number, err)
+}
+
+// deliver passes a parsed token on to visit. Invalid tokens count as zero,
+// unless in strict mode.
+func (s *streamSplitter) deliver(token pendingToken, number parsedNumber, err error) error {
+	value := Value{Index: s.index, Offset: token.offset, Value: number.value, Currency: number.currency, Fraction: number.fraction}
+	s.index++
+	if err != nil {
+		value.Invalid = newInvalidTokenError(token.text, value.Index, token.offset, err)
+		if s.validator.strict || value.Invalid.Rejected {
+			return &InvalidTokensError{Tokens: []*InvalidTokenError{value.Invalid}}
+		}
+	}
+
+	if value.Value.Sign() == -1 && s.validator.negatives == NegativesError {
+		return &NegativeNumbersError{Values: []decimal.Decimal{value.Value}, Positions: []int{token.offset}}
+	}
+	return s.visit(value)
+}
+
+// pendingToken is a token waiting to be parsed. err is set when it is
+// already known to be invalid.
+type pendingToken struct {
+	text   string
+	offset int
+	err    error
+}
+
+func (t pendingToken) parse(parse parseFunc) (parsedNumber, error) {
+	switch t.err {
+	case nil:
+		return parse(t.text)
+	case errMissingNumber:
+		// Missing numbers read as "0", as in sanitizeInput.
+		return parsedNumber{value: decimal.NewFromInt(0)}, t.err
+	}
+	return parsedNumber{value: decimal.Zero}, t.err
+}
+
+// unescapeReader applies UnescapeNewline to a stream.
+type unescapeReader struct {
+	r *bufio.Reader
+}
+
+// NewUnescapeReader returns a reader that replaces each `\n` read from r
+// with a newline, as UnescapeNewline does for a string.
+func NewUnescapeReader(r io.Reader) io.Reader {
+	return &unescapeReader{r: bufio.NewReader(r)}
+}
+
+func (u *unescapeReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		// Return what is already read rather than wait for more input.
+		if n > 0 && u.r.Buffered() == 0 {
+			break
+		}
+		c, err := u.r.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		if c == '\\' {
+			if next, err := u.r.Peek(1); err == nil && next[0] == 'n' {
+				u.r.Discard(1)
+				c = '\n'
+			}
+		}
+		p[n] = c
+		n++
+	}
+	return n, nil
+}
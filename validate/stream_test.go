@@ -0,0 +1,105 @@
+package validate
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// parseStreamAll collects the values visited by ParseStream in the shape
+// returned by Parse.
+func parseStreamAll(v *Validator, input string) (*Parsed, error) {
+	parsed := &Parsed{}
+	warnings, err := v.ParseStream(strings.NewReader(input), func(value Value) error {
+		parsed.Values = append(parsed.Values, value.Value)
+		parsed.Offsets = append(parsed.Offsets, value.Offset)
+		if value.Invalid != nil {
+			parsed.InvalidTokens = append(parsed.InvalidTokens, value.Invalid)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	parsed.Warnings = warnings
+	return parsed, nil
+}
+
+func TestParseStreamMatchesParse(t *testing.T) {
+	inputs := []string{
+		"",
+		"   \n\t ",
+		"1,2,3",
+		"1,2\n3",
+		"  1 , 2 ,3  ",
+		"1,,3",
+		"1,2,",
+		"1,2,\n",
+		"1,2\n\n",
+		"\n\n1,2",
+		"1\n\n,2",
+		"1\n\n,",
+		"1, ,2",
+		"1,abc,3",
+		"1.5,2.25,-0",
+		"1e3,+5,.5",
+		"//;\n1;2;3",
+		"//[***][*]\n1***2*3,4",
+		"//[\\t]\n1\t2\t\t3",
+		"//§\n1§2§3",
+		"1 2,3　",
+		"1000,1001,2",
+		strings.Repeat("12,", 100) + "7",
+		strings.Repeat("1\n", 50),
+	}
+
+	defer func(size int) { streamBufferSize = size }(streamBufferSize)
+	for _, size := range []int{16, 32, 4096} {
+		streamBufferSize = size
+		for _, input := range inputs {
+			expected, expectedErr := New().Parse(input)
+			actual, err := parseStreamAll(New(), input)
+			assert.Equal(t, expectedErr, err, "buffer %d, input %q", size, input)
+			assert.Equal(t, expected, actual, "buffer %d, input %q", size, input)
+		}
+	}
+}
+
+func TestParseStreamErrors(t *testing.T) {
+	// The first negative number stops the stream before it is visited.
+	visited := 0
+	_, err := New().ParseStream(strings.NewReader("1,-2,3,-4"), func(Value) error {
+		visited++
+		return nil
+	})
+	var negativeErr *NegativeNumbersError
+	assert.True(t, errors.As(err, &negativeErr))
+	assert.Equal(t, []int{2}, negativeErr.Positions)
+	assert.Equal(t, 1, visited)
+
+	_, err = parseStreamAll(New(WithStrict(true)), "1,x,y")
+	assert.EqualError(t, err, `invalid input: invalid tokens found: "x" at index 1 (offset 2): not a number`)
+
+	_, err = parseStreamAll(New(), "//-\n1-2")
+	assert.True(t, errors.Is(err, ErrAmbiguousDelimiter))
+
+	_, err = parseStreamAll(New(), "//re:;\n1;2")
+	assert.Equal(t, ErrStreamPattern, err)
+
+	parsed, err := parseStreamAll(New(), "1,"+strings.Repeat("9", maxStreamTokenLength+1))
+	assert.NoError(t, err)
+	assert.Len(t, parsed.InvalidTokens, 1)
+	assert.Equal(t, "token too long", parsed.InvalidTokens[0].Reason)
+}
+
+func TestUnescapeReader(t *testing.T) {
+	inputs := []string{"", `1\n2`, `\\n`, `\`, `1\`, `\n\n`, strings.Repeat(`1\n`, 5000)}
+	for _, input := range inputs {
+		output, err := io.ReadAll(NewUnescapeReader(strings.NewReader(input)))
+		assert.NoError(t, err)
+		assert.Equal(t, UnescapeNewline(input), string(output), "input %q", input)
+	}
+}
@@ -0,0 +1,194 @@
+package validate
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token is a single value from the input. Offset and End give the byte span
+// of the trimmed text in the input, and Index its position among the values.
+type Token struct {
+	Text   string
+	Index  int
+	Offset int
+	End    int
+}
+
+// tokenizer splits input in a single pass. At each position it matches the
+// longest delimiter that starts there, so the result does not depend on the
+// order delimiters were declared in. Patterns compete with the literal
+// delimiters on match length.
+type tokenizer struct {
+	// byFirstByte holds the delimiters starting with each byte, longest first.
+	byFirstByte [256][]string
+	// patterns are anchored to the position being matched.
+	patterns []*regexp.Regexp
+	// quotes keeps text between double quotes in one token.
+	quotes bool
+}
+
+func newTokenizer(delimiters []string, patterns []*regexp.Regexp) *tokenizer {
+	t := &tokenizer{}
+	for _, pattern := range patterns {
+		anchored := regexp.MustCompile(`^(?:` + pattern.String() + `)`)
+		anchored.Longest()
+		t.patterns = append(t.patterns, anchored)
+	}
+	for _, delimiter := range delimiters {
+		if delimiter == "" {
+			continue
+		}
+		first := delimiter[0]
+		if !containsString(t.byFirstByte[first], delimiter) {
+			t.byFirstByte[first] = append(t.byFirstByte[first], delimiter)
+		}
+	}
+	for i := range t.byFirstByte {
+		candidates := t.byFirstByte[i]
+		sort.SliceStable(candidates, func(a, b int) bool {
+			return len(candidates[a]) > len(candidates[b])
+		})
+	}
+	return t
+}
+
+// newTokenizer returns a tokenizer for the validator, which keeps quoted
+// numbers together when a locale is set.
+func (v *Validator) newTokenizer(delimiters []string, patterns []*regexp.Regexp) *tokenizer {
+	t := newTokenizer(delimiters, patterns)
+	t.quotes = v.locale != nil
+	return t
+}
+
+// matchAt returns the length of the longest delimiter starting at pos, or 0.
+// Empty pattern matches do not count.
+func (t *tokenizer) matchAt(input string, pos int) int {
+	length := 0
+	for _, delimiter := range t.byFirstByte[input[pos]] {
+		if strings.HasPrefix(input[pos:], delimiter) {
+			length = len(delimiter)
+			break
+		}
+	}
+	for _, pattern := range t.patterns {
+		if match := pattern.FindStringIndex(input[pos:]); match != nil && match[1] > length {
+			length = match[1]
+		}
+	}
+	return length
+}
+
+// split trims input, splits it at delimiters and trims each token.
+func (t *tokenizer) split(input string) []Token {
+	base, end := trimSpan(input, 0, len(input))
+
+	// Counting first keeps a multi-megabyte input from regrowing the slice.
+	tokens := make([]Token, 0, t.count(input[:end], base)+1)
+	start := base
+	pos := base
+	for pos < end {
+		if t.quotes && input[pos] == '"' {
+			pos = skipQuoted(input[:end], pos)
+			continue
+		}
+		length := t.matchAt(input[:end], pos)
+		if length == 0 {
+			pos++
+			continue
+		}
+		tokens = append(tokens, newToken(input, start, pos, len(tokens)))
+		pos += length
+		start = pos
+	}
+	return append(tokens, newToken(input, start, end, len(tokens)))
+}
+
+// count returns the number of delimiters in input from pos onwards.
+func (t *tokenizer) count(input string, pos int) int {
+	n := 0
+	for pos < len(input) {
+		if t.quotes && input[pos] == '"' {
+			pos = skipQuoted(input, pos)
+		} else if length := t.matchAt(input, pos); length > 0 {
+			n++
+			pos += length
+		} else {
+			pos++
+		}
+	}
+	return n
+}
+
+// skipQuoted returns the position after the quote that closes the one at
+// pos, or the end of input if it is not closed.
+func skipQuoted(input string, pos int) int {
+	if end := strings.IndexByte(input[pos+1:], '"'); end != -1 {
+		return pos + end + 2
+	}
+	return len(input)
+}
+
+func newToken(input string, start, end, index int) Token {
+	start, end = trimSpan(input, start, end)
+	return Token{
+		Text:   input[start:end],
+		Index:  index,
+		Offset: start,
+		End:    end,
+	}
+}
+
+// trimSpan narrows [start, end) to exclude surrounding whitespace. ASCII is
+// checked directly since it is by far the common case.
+func trimSpan(input string, start, end int) (int, int) {
+	for start < end {
+		c := input[start]
+		if c < utf8.RuneSelf {
+			if !asciiSpace[c] {
+				break
+			}
+			start++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(input[start:end])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		start += size
+	}
+	for end > start {
+		c := input[end-1]
+		if c < utf8.RuneSelf {
+			if !asciiSpace[c] {
+				break
+			}
+			end--
+			continue
+		}
+		r, size := utf8.DecodeLastRuneInString(input[start:end])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		end -= size
+	}
+	return start, end
+}
+
+var asciiSpace = [utf8.RuneSelf]bool{'\t': true, '\n': true, '\v': true, '\f': true, '\r': true, ' ': true}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// splitTokens splits input on delimiters. Offsets are byte offsets into input.
+func splitTokens(input string, delimiters []string) []Token {
+	return newTokenizer(delimiters, nil).split(input)
+}
@@ -0,0 +1,169 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		delims   []string
+		expected []Token
+	}{
+		{
+			name:   "offsets skip whitespace",
+			input:  "  1 , 22 ,3",
+			delims: []string{","},
+			expected: []Token{
+				{Text: "1", Index: 0, Offset: 2, End: 3},
+				{Text: "22", Index: 1, Offset: 6, End: 8},
+				{Text: "3", Index: 2, Offset: 10, End: 11},
+			},
+		},
+		{
+			name:   "empty tokens",
+			input:  ",1,,",
+			delims: []string{","},
+			expected: []Token{
+				{Text: "", Index: 0, Offset: 0, End: 0},
+				{Text: "1", Index: 1, Offset: 1, End: 2},
+				{Text: "", Index: 2, Offset: 3, End: 3},
+				{Text: "", Index: 3, Offset: 4, End: 4},
+			},
+		},
+		{
+			name:   "multi-character delimiters",
+			input:  "11r9r22*hh",
+			delims: []string{"*", "r9r"},
+			expected: []Token{
+				{Text: "11", Index: 0, Offset: 0, End: 2},
+				{Text: "22", Index: 1, Offset: 5, End: 7},
+				{Text: "hh", Index: 2, Offset: 8, End: 10},
+			},
+		},
+		{
+			name:   "longest delimiter wins regardless of order",
+			input:  "1**2*3",
+			delims: []string{"*", "**"},
+			expected: []Token{
+				{Text: "1", Index: 0, Offset: 0, End: 1},
+				{Text: "2", Index: 1, Offset: 3, End: 4},
+				{Text: "3", Index: 2, Offset: 5, End: 6},
+			},
+		},
+		{
+			name:   "delimiter containing a comma",
+			input:  "1,;2,3",
+			delims: []string{",;"},
+			expected: []Token{
+				{Text: "1", Index: 0, Offset: 0, End: 1},
+				{Text: "2,3", Index: 1, Offset: 3, End: 6},
+			},
+		},
+		{
+			name:   "empty delimiters are ignored",
+			input:  "12",
+			delims: []string{"", ","},
+			expected: []Token{
+				{Text: "12", Index: 0, Offset: 0, End: 2},
+			},
+		},
+		{
+			name:   "trailing newline trimmed before splitting",
+			input:  "1\n\n2\n",
+			delims: []string{",", "\n"},
+			expected: []Token{
+				{Text: "1", Index: 0, Offset: 0, End: 1},
+				{Text: "", Index: 1, Offset: 2, End: 2},
+				{Text: "2", Index: 2, Offset: 3, End: 4},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, splitTokens(test.input, test.delims))
+		})
+	}
+}
+
+func TestDelimiterOrderDoesNotMatter(t *testing.T) {
+	first, err := New().ValidateInput("//[*][**]\n1**2*3")
+	assert.NoError(t, err)
+	second, err := New().ValidateInput("//[**][*]\n1**2*3")
+	assert.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.Len(t, first, 3)
+}
+
+// replaceAllSplit is the previous ReplaceAll implementation of splitting,
+// kept as the baseline for the benchmarks below.
+func replaceAllSplit(input string, delimiters []string) []string {
+	result := strings.TrimSpace(input)
+	for _, delimiter := range delimiters {
+		result = strings.ReplaceAll(result, delimiter, ",")
+	}
+
+	parts := strings.Split(result, ",")
+	cleanParts := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			cleanParts = append(cleanParts, trimmed)
+		} else {
+			cleanParts = append(cleanParts, "0")
+		}
+	}
+	return cleanParts
+}
+
+func benchmarkInput(size int, delimiters []string) string {
+	var sb strings.Builder
+	for i := 0; sb.Len() < size; i++ {
+		if i > 0 {
+			sb.WriteString(delimiters[i%len(delimiters)])
+		}
+		sb.WriteString("123.45")
+	}
+	return sb.String()
+}
+
+var benchmarkCases = []struct {
+	name       string
+	size       int
+	delimiters []string
+}{
+	{name: "1MB default", size: 1 << 20, delimiters: []string{",", "\n"}},
+	{name: "1MB custom", size: 1 << 20, delimiters: []string{"***", "!!", "r9r", ",", "\n"}},
+	{name: "8MB custom", size: 8 << 20, delimiters: []string{"***", "!!", "r9r", ",", "\n"}},
+}
+
+func BenchmarkSplitTokens(b *testing.B) {
+	for _, bc := range benchmarkCases {
+		input := benchmarkInput(bc.size, bc.delimiters)
+		b.Run(bc.name, func(b *testing.B) {
+			b.SetBytes(int64(len(input)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				splitTokens(input, bc.delimiters)
+			}
+		})
+	}
+}
+
+func BenchmarkReplaceAllSplit(b *testing.B) {
+	for _, bc := range benchmarkCases {
+		input := benchmarkInput(bc.size, bc.delimiters)
+		b.Run(bc.name, func(b *testing.B) {
+			b.SetBytes(int64(len(input)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				replaceAllSplit(input, bc.delimiters)
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package validate
 
 import (
-	"fmt"
+	"errors"
+	"math/big"
+	"regexp"
 	"strings"
 
 	"challenge-calculator/logger"
@@ -9,153 +11,341 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// Package-level settings used by the free functions. Callers that need
+// isolated settings should build their own Validator with New.
 var (
 	defaultDelimiters = []string{","}
-	customDelimiters  []string
-	allowNegatives    bool
+	negatives         = NegativesError
 )
 
+var (
+	errMissingNumber = errors.New("missing number")
+	errNotANumber    = errors.New("not a number")
+	errExponentRange = errors.New("exponent out of range")
+)
+
+// Validator parses delimiter-separated input using its own settings, so a
+// single value can be shared safely between goroutines.
+type Validator struct {
+	delimiters  []string
+	patterns    []*regexp.Regexp
+	negatives   NegativesPolicy
+	strict      bool
+	workers     int
+	locale      *Locale
+	formats     []Format
+	currency    bool
+	fractions   bool
+	maxExponent int32
+	// defaultComma is set while delimiters[0] is the "," accepted by
+	// default, rather than one that was asked for.
+	defaultComma bool
+}
+
+type Option func(*Validator)
+
+// WithDefaultDelimiter sets the delimiter accepted alongside ",".
+func WithDefaultDelimiter(delimiter string) Option {
+	return func(v *Validator) {
+		v.delimiters = []string{",", delimiter}
+		v.defaultComma = true
+	}
+}
+
+// WithDelimiters replaces the full default delimiter set.
+func WithDelimiters(delimiters ...string) Option {
+	return func(v *Validator) {
+		v.delimiters = append([]string{}, delimiters...)
+		v.defaultComma = false
+	}
+}
+
+// WithDelimiterPattern adds a regular expression that matches delimiters,
+// alongside the literal ones.
+func WithDelimiterPattern(pattern *regexp.Regexp) Option {
+	return func(v *Validator) {
+		v.patterns = append(v.patterns, pattern)
+	}
+}
+
+// WithAllowNegatives chooses between NegativesAllow and NegativesError.
+func WithAllowNegatives(allow bool) Option {
+	return WithNegatives(allowPolicy(allow))
+}
+
+func allowPolicy(allow bool) NegativesPolicy {
+	if allow {
+		return NegativesAllow
+	}
+	return NegativesError
+}
+
+// WithStrict makes tokens that are not numbers, including empty ones, an
+// error instead of zero.
+func WithStrict(strict bool) Option {
+	return func(v *Validator) {
+		v.strict = strict
+	}
+}
+
+// WithMaxExponent rejects numbers whose exponent is beyond max in either
+// direction, such as 1e-50000000, even outside strict mode. Calculating with
+// them takes time and memory out of all proportion to the input. Fractions
+// are also rejected if any part of them has more than max digits.
+func WithMaxExponent(max int32) Option {
+	return func(v *Validator) {
+		v.maxExponent = max
+	}
+}
+
+// New returns a Validator that accepts "," and "\n" as delimiters and
+// rejects negative numbers unless configured otherwise.
+func New(opts ...Option) *Validator {
+	v := &Validator{
+		delimiters:   []string{",", "\n"},
+		negatives:    NegativesError,
+		defaultComma: true,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	if v.locale != nil && v.defaultComma {
+		v.delimiters[0] = v.locale.Delimiter
+	}
+	if v.currency && !hasCurrencyFormat(v.formats) {
+		v.formats = append(v.formats, currencyFormat{})
+	}
+	return v
+}
+
+// Default returns a Validator built from the package-level settings.
+func Default() *Validator {
+	return New(WithDelimiters(defaultDelimiters...), WithNegatives(negatives))
+}
+
 func SetDefaultDelimiter(delimiter string) {
-	defaultDelimiters = append(defaultDelimiters[:1], delimiter)
+	defaultDelimiters = append(defaultDelimiters[:1:1], delimiter)
 }
 
 func SetAllowNegatives(allow bool) {
-	allowNegatives = allow
+	negatives = allowPolicy(allow)
 }
 
 func ValidateInput(input string) ([]decimal.Decimal, error) {
-	logger.Debug(fmt.Sprintf("Starting input validation: %s", input))
-
-	// Reset custom delimiters for each validation
-	customDelimiters = nil
+	return Default().ValidateInput(input)
+}
 
-	modifiedInput, err := processCustomDelimiters(input)
+func (v *Validator) ValidateInput(input string) ([]decimal.Decimal, error) {
+	parsed, err := v.Parse(input)
 	if err != nil {
 		return nil, err
 	}
+	return parsed.Values, nil
+}
+
+// Parsed is the outcome of Parse. Offsets holds the byte offset of each value
+// in the input. Currencies holds the ISO 4217 code each value was written
+// with, or "", and is nil unless formats are enabled. Fractions holds the
+// exact value of each value written as a fraction, or nil, and is nil unless
+// fractions are enabled. InvalidTokens lists the tokens that were treated as
+// zero; it is always empty in strict mode, where they are an error. Warnings
+// describe delimiters that contain one another.
+type Parsed struct {
+	Values        []decimal.Decimal
+	Currencies    []string
+	Fractions     []*big.Rat
+	Offsets       []int
+	InvalidTokens []*InvalidTokenError
+	Warnings      []string
+}
+
+func (v *Validator) Parse(input string) (*Parsed, error) {
+	logger.DebugFields("Starting input validation", logger.Fields{"input": input})
 
-	sanitizedValues, err := sanitizeInput(modifiedInput)
+	modifiedInput, customPattern, err := processRegexHeader(input)
 	if err != nil {
 		return nil, err
 	}
-
-	if !allowNegatives {
-		negativeNumbers := findNegativeNumbers(sanitizedValues)
-		if len(negativeNumbers) > 0 {
-			return nil, fmt.Errorf("invalid input: negative numbers found: %s", strings.Join(negativeNumbers, ", "))
+	var customDelimiters []string
+	var customOffsets []int
+	if customPattern == nil {
+		modifiedInput, customDelimiters, customOffsets, err = processCustomDelimiters(input)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	return sanitizedValues, nil
-}
+	warnings, err := v.checkDelimiters(v.patterns, customDelimiters, customOffsets, customPattern)
+	if err != nil {
+		return nil, err
+	}
 
-func processCustomDelimiters(input string) (string, error) {
-	if !strings.HasPrefix(input, "//") {
-		return input, nil
+	patterns := v.patterns
+	if customPattern != nil {
+		patterns = append(patterns[:len(patterns):len(patterns)], customPattern)
 	}
+	tokenizer := v.newTokenizer(append(customDelimiters, v.delimiters...), patterns)
+	parsed := v.sanitizeInput(modifiedInput, tokenizer)
 
-	delimiterEnd := strings.Index(input, "\n")
-	if delimiterEnd == -1 {
-		return input, nil
+	// Report offsets against the input as given, header included.
+	headerLength := len(input) - len(modifiedInput)
+	for i := range parsed.Offsets {
+		parsed.Offsets[i] += headerLength
+	}
+	for _, invalid := range parsed.InvalidTokens {
+		invalid.Offset += headerLength
 	}
 
-	// Extract the delimiter definition part (without the //)
-	delimiterDef := input[2:delimiterEnd]
+	if v.strict && len(parsed.InvalidTokens) > 0 {
+		return nil, &InvalidTokensError{Tokens: parsed.InvalidTokens}
+	}
+	if rejected := rejectedTokens(parsed.InvalidTokens); len(rejected) > 0 {
+		return nil, &InvalidTokensError{Tokens: rejected}
+	}
 
-	if strings.HasPrefix(delimiterDef, "[") {
-		startIdx := 0
-		for startIdx < len(delimiterDef) {
-			openBracket := strings.IndexRune(delimiterDef[startIdx:], '[')
-			if openBracket == -1 {
-				break
-			}
-			openBracket += startIdx
+	if err := v.CheckNegatives(parsed.Values, parsed.Offsets); err != nil {
+		return nil, err
+	}
 
-			closeBracket := strings.IndexRune(delimiterDef[openBracket:], ']')
-			if closeBracket == -1 {
-				return input, fmt.Errorf("invalid delimiter format: missing closing bracket")
-			}
-			closeBracket += openBracket
-
-			// Extract the delimiter between brackets
-			if closeBracket-openBracket > 1 {
-				delimiter := delimiterDef[openBracket+1 : closeBracket]
-				if delimiter != "" {
-					customDelimiters = append(customDelimiters, delimiter)
-				}
-			}
+	parsed.Warnings = warnings
+	return parsed, nil
+}
+
+// CheckNegatives applies the negative-number policy to numbers that were
+// parsed elsewhere, such as the literals of an expression. offsets gives the
+// position of each number and is reported back in NegativeNumbersError.
+func (v *Validator) CheckNegatives(numbers []decimal.Decimal, offsets []int) error {
+	if v.negatives != NegativesError {
+		return nil
+	}
 
-			startIdx = closeBracket + 1
+	var negativeErr *NegativeNumbersError
+	for i, number := range numbers {
+		if number.Sign() != -1 {
+			continue
 		}
-	} else {
-		if len(delimiterDef) != 1 {
-			return input, fmt.Errorf("invalid custom delimiter: %q", delimiterDef)
+		if negativeErr == nil {
+			negativeErr = &NegativeNumbersError{}
 		}
-		customDelimiters = append(customDelimiters, delimiterDef)
+		negativeErr.Values = append(negativeErr.Values, number)
+		negativeErr.Positions = append(negativeErr.Positions, offsets[i])
 	}
-
-	// Return the input with delimiter definition removed
-	return input[delimiterEnd+1:], nil
+	if negativeErr != nil {
+		return negativeErr
+	}
+	return nil
 }
 
-func sanitizeInput(input string) ([]decimal.Decimal, error) {
-	logger.Debug(fmt.Sprintf("Starting input sanitization: %s", input))
+func (v *Validator) sanitizeInput(input string, tokenizer *tokenizer) *Parsed {
+	logger.DebugFields("Starting input sanitization", logger.Fields{"input": input})
 
 	if len(strings.TrimSpace(input)) == 0 {
 		logger.Debug("Empty input received, returning [0]")
-		return []decimal.Decimal{decimal.Zero}, nil
+		parsed := v.newParsed(1)
+		parsed.set(0, parsedNumber{value: decimal.Zero}, 0)
+		return parsed
 	}
 
-	splitValues := splitInput(input)
+	tokens := tokenizer.split(input)
+	parsed := v.newParsed(len(tokens))
+	parseTokens(tokens, v.workers, v.parseToken, parsed)
 
-	var sanitizedValues []decimal.Decimal
-	for _, number := range splitValues {
-		convertedNumber := parseDecimal(number)
-		sanitizedValues = append(sanitizedValues, convertedNumber)
-	}
+	logger.DebugFields("Input sanitization completed", logger.Fields{"value_count": len(parsed.Values), "invalid_count": len(parsed.InvalidTokens)})
+	return parsed
+}
 
-	logger.Debug(fmt.Sprintf("Input sanitization completed: %v", sanitizedValues))
-	return sanitizedValues, nil
+// newParsed returns a Parsed with room for n values, including currencies
+// and fractions when they are enabled.
+func (v *Validator) newParsed(n int) *Parsed {
+	parsed := &Parsed{Values: make([]decimal.Decimal, n), Offsets: make([]int, n)}
+	if len(v.formats) > 0 {
+		parsed.Currencies = make([]string, n)
+	}
+	if v.fractions {
+		parsed.Fractions = make([]*big.Rat, n)
+	}
+	return parsed
 }
 
-func splitInput(input string) []string {
-	trimmedInput := strings.TrimSpace(input)
-	result := trimmedInput
+// parseToken converts the text of a token, using the locale, formats and
+// fractions if they are enabled.
+func (v *Validator) parseToken(text string) (parsedNumber, error) {
+	invalid := parsedNumber{value: decimal.Zero}
+	if v.locale != nil {
+		var err error
+		if text, err = unquote(text); err != nil {
+			return invalid, err
+		}
+	}
 
-	allDelimiters := append([]string{}, customDelimiters...)
-	allDelimiters = append(allDelimiters, defaultDelimiters...)
+	var match Match
+	if len(v.formats) > 0 {
+		var err error
+		if match, err = recognize(v.formats, text); err != nil {
+			return invalid, err
+		}
+		text = match.Text
+	}
 
-	separator := ","
-	for _, delimiter := range allDelimiters {
-		result = strings.ReplaceAll(result, delimiter, separator)
+	if v.fractions {
+		fraction, ok, err := parseFraction(text, v.maxExponent)
+		if err != nil {
+			return invalid, err
+		}
+		if ok {
+			if v.maxExponent > 0 && (match.Exponent > v.maxExponent || match.Exponent < -v.maxExponent) {
+				return invalid, errExponentRange
+			}
+			if fraction, err = match.applyFraction(fraction); err != nil {
+				return invalid, err
+			}
+			return parsedNumber{value: fractionValue(fraction), currency: match.Currency, fraction: fraction}, nil
+		}
 	}
 
-	parts := strings.Split(result, separator)
-	var cleanParts []string
+	var number decimal.Decimal
+	var err error
+	if v.locale != nil {
+		number, err = v.locale.parse(text)
+	} else {
+		number, err = parseNumber(text)
+	}
+	if err != nil {
+		return parsedNumber{value: number}, err
+	}
+	number, err = match.apply(number)
+	if err != nil {
+		return parsedNumber{value: number}, err
+	}
+	if v.maxExponent > 0 && (number.Exponent() > v.maxExponent || number.Exponent() < -v.maxExponent) {
+		return invalid, errExponentRange
+	}
+	return parsedNumber{value: number, currency: match.Currency}, nil
+}
 
-	for _, part := range parts {
-		trimmed := strings.TrimSpace(part)
-		if trimmed != "" {
-			cleanParts = append(cleanParts, trimmed)
-		} else {
-			cleanParts = append(cleanParts, "0")
+// rejectedTokens returns the invalid tokens that are an error even outside
+// strict mode.
+func rejectedTokens(tokens []*InvalidTokenError) []*InvalidTokenError {
+	var rejected []*InvalidTokenError
+	for _, token := range tokens {
+		if token.Rejected {
+			rejected = append(rejected, token)
 		}
 	}
-
-	return cleanParts
+	return rejected
 }
 
-func parseDecimal(val string) decimal.Decimal {
+// parseNumber converts a single token, reporting why it is not a number.
+func parseNumber(val string) (decimal.Decimal, error) {
 	if val == "" {
-		return decimal.Zero
+		return decimal.Zero, errMissingNumber
 	}
 
 	number, err := decimal.NewFromString(val)
 	if err != nil {
-		logger.Debug(fmt.Sprintf("Invalid number format '%s', converting to 0", val))
-		return decimal.Zero
+		return decimal.Zero, errNotANumber
 	}
-	return number
+	return number, nil
 }
 
 func UnescapeNewline(input string) string {
@@ -1,6 +1,7 @@
 package validate
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/shopspring/decimal"
@@ -154,141 +155,116 @@ func TestValidateInput(t *testing.T) {
 
 func TestSanitizeInput(t *testing.T) {
 	tests := []struct {
-		name        string
-		input       string
-		expected    []decimal.Decimal
-		expectedErr error
+		name     string
+		input    string
+		expected []decimal.Decimal
 	}{
 		{
-			name:        "valid input with two numbers",
-			input:       "1,2",
-			expected:    []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2)},
-			expectedErr: nil,
+			name:     "valid input with two numbers",
+			input:    "1,2",
+			expected: []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2)},
 		},
 		{
-			name:        "newline delimiter",
-			input:       "1\n2",
-			expected:    []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2)},
-			expectedErr: nil,
+			name:     "newline delimiter",
+			input:    "1\n2",
+			expected: []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2)},
 		},
 		{
-			name:        "mixed delimiters",
-			input:       "1\n2,3",
-			expected:    []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2), decimal.NewFromInt(3)},
-			expectedErr: nil,
+			name:     "mixed delimiters",
+			input:    "1\n2,3",
+			expected: []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2), decimal.NewFromInt(3)},
 		},
 		{
-			name:        "newline with whitespace",
-			input:       " 1 \n 2 ",
-			expected:    []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2)},
-			expectedErr: nil,
+			name:     "newline with whitespace",
+			input:    " 1 \n 2 ",
+			expected: []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2)},
 		},
 		{
-			name:        "multiple newlines",
-			input:       "1\n\n2",
-			expected:    []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(0), decimal.NewFromInt(2)},
-			expectedErr: nil,
+			name:     "multiple newlines",
+			input:    "1\n\n2",
+			expected: []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(0), decimal.NewFromInt(2)},
 		},
 		{
-			name:        "newline with empty lines",
-			input:       "1\n\n2\n",
-			expected:    []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(0), decimal.NewFromInt(2)},
-			expectedErr: nil,
+			name:     "newline with empty lines",
+			input:    "1\n\n2\n",
+			expected: []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(0), decimal.NewFromInt(2)},
 		},
 		{
-			name:        "single number",
-			input:       "123",
-			expected:    []decimal.Decimal{decimal.NewFromInt(123)},
-			expectedErr: nil,
+			name:     "single number",
+			input:    "123",
+			expected: []decimal.Decimal{decimal.NewFromInt(123)},
 		},
 		{
-			name:        "two numbers",
-			input:       "1,5",
-			expected:    []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(5)},
-			expectedErr: nil,
+			name:     "two numbers",
+			input:    "1,5",
+			expected: []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(5)},
 		},
 		{
-			name:        "empty string",
-			input:       "",
-			expected:    []decimal.Decimal{decimal.Zero},
-			expectedErr: nil,
+			name:     "empty string",
+			input:    "",
+			expected: []decimal.Decimal{decimal.Zero},
 		},
 		{
-			name:        "mixed positive and negative",
-			input:       "4,-3",
-			expected:    []decimal.Decimal{decimal.NewFromInt(4), decimal.NewFromInt(-3)},
-			expectedErr: nil,
+			name:     "mixed positive and negative",
+			input:    "4,-3",
+			expected: []decimal.Decimal{decimal.NewFromInt(4), decimal.NewFromInt(-3)},
 		},
 		{
-			name:        "many numbers",
-			input:       "1,2,3",
-			expected:    []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2), decimal.NewFromInt(3)},
-			expectedErr: nil,
+			name:     "many numbers",
+			input:    "1,2,3",
+			expected: []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2), decimal.NewFromInt(3)},
 		},
 		{
-			name:        "negative number",
-			input:       "-123,456",
-			expected:    []decimal.Decimal{decimal.NewFromInt(-123), decimal.NewFromInt(456)},
-			expectedErr: nil,
+			name:     "negative number",
+			input:    "-123,456",
+			expected: []decimal.Decimal{decimal.NewFromInt(-123), decimal.NewFromInt(456)},
 		},
 		{
-			name:        "decimal numbers",
-			input:       "123.45,67.89",
-			expected:    []decimal.Decimal{decimal.NewFromFloat(123.45), decimal.NewFromFloat(67.89)},
-			expectedErr: nil,
+			name:     "decimal numbers",
+			input:    "123.45,67.89",
+			expected: []decimal.Decimal{decimal.NewFromFloat(123.45), decimal.NewFromFloat(67.89)},
 		},
 		{
-			name:        "missing first number",
-			input:       ",12",
-			expected:    []decimal.Decimal{decimal.NewFromInt(0), decimal.NewFromInt(12)},
-			expectedErr: nil,
+			name:     "missing first number",
+			input:    ",12",
+			expected: []decimal.Decimal{decimal.NewFromInt(0), decimal.NewFromInt(12)},
 		},
 		{
-			name:        "missing second number",
-			input:       "12,",
-			expected:    []decimal.Decimal{decimal.NewFromInt(12), decimal.NewFromInt(0)},
-			expectedErr: nil,
+			name:     "missing second number",
+			input:    "12,",
+			expected: []decimal.Decimal{decimal.NewFromInt(12), decimal.NewFromInt(0)},
 		},
 		{
-			name:        "missing both numbers",
-			input:       ",",
-			expected:    []decimal.Decimal{decimal.NewFromInt(0), decimal.NewFromInt(0)},
-			expectedErr: nil,
+			name:     "missing both numbers",
+			input:    ",",
+			expected: []decimal.Decimal{decimal.NewFromInt(0), decimal.NewFromInt(0)},
 		},
 		{
-			name:        "invalid number in second position",
-			input:       "5,tytyt",
-			expected:    []decimal.Decimal{decimal.NewFromInt(5), decimal.Zero},
-			expectedErr: nil,
+			name:     "invalid number in second position",
+			input:    "5,tytyt",
+			expected: []decimal.Decimal{decimal.NewFromInt(5), decimal.Zero},
 		},
 		{
-			name:        "invalid number in first position",
-			input:       "tytyt,5",
-			expected:    []decimal.Decimal{decimal.Zero, decimal.NewFromInt(5)},
-			expectedErr: nil,
+			name:     "invalid number in first position",
+			input:    "tytyt,5",
+			expected: []decimal.Decimal{decimal.Zero, decimal.NewFromInt(5)},
 		},
 		{
-			name:        "whitespace handling",
-			input:       " 1 , 2 ",
-			expected:    []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2)},
-			expectedErr: nil,
+			name:     "whitespace handling",
+			input:    " 1 , 2 ",
+			expected: []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2)},
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			result, err := sanitizeInput(test.input)
-			if test.expectedErr != nil {
-				assert.Equal(t, test.expectedErr, err)
-			} else {
-				assert.Equal(t, test.expected, result)
-				assert.NoError(t, err)
-			}
+			result := New().sanitizeInput(test.input, newTokenizer([]string{",", "\n"}, nil)).Values
+			assert.Equal(t, test.expected, result)
 		})
 	}
 }
 
-func TestSplitInput(t *testing.T) {
+func TestSplitTokensText(t *testing.T) {
 	tests := []struct {
 		name     string
 		input    string
@@ -311,25 +287,25 @@ func TestSplitInput(t *testing.T) {
 			name:     "empty string",
 			input:    "",
 			delims:   nil,
-			expected: []string{"0"},
+			expected: []string{""},
 		},
 		{
 			name:     "missing first number",
 			input:    ",12",
 			delims:   nil,
-			expected: []string{"0", "12"},
+			expected: []string{"", "12"},
 		},
 		{
 			name:     "missing second number",
 			input:    "12,",
 			delims:   nil,
-			expected: []string{"12", "0"},
+			expected: []string{"12", ""},
 		},
 		{
 			name:     "missing both numbers",
 			input:    ",",
 			delims:   nil,
-			expected: []string{"0", "0"},
+			expected: []string{"", ""},
 		},
 		{
 			name:     "whitespace handling",
@@ -377,34 +353,34 @@ func TestSplitInput(t *testing.T) {
 			name:     "consecutive delimiters",
 			input:    "1,,2",
 			delims:   nil,
-			expected: []string{"1", "0", "2"},
+			expected: []string{"1", "", "2"},
 		},
 		{
 			name:     "delimiter at start and end",
 			input:    ",1,2,",
 			delims:   nil,
-			expected: []string{"0", "1", "2", "0"},
+			expected: []string{"", "1", "2", ""},
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			// Set custom delimiters for this test
-			customDelimiters = test.delims
-
-			// Set the default delimiter for this test, since no flag is provided
-			SetDefaultDelimiter("\n")
-			result := splitInput(test.input)
+			delimiters := append(test.delims, ",", "\n")
+			var result []string
+			for _, token := range splitTokens(test.input, delimiters) {
+				result = append(result, token.Text)
+			}
 			assert.Equal(t, test.expected, result)
 		})
 	}
 }
 
-func TestParseDecimal(t *testing.T) {
+func TestParseNumber(t *testing.T) {
 	tests := []struct {
-		name     string
-		input    string
-		expected decimal.Decimal
+		name        string
+		input       string
+		expected    decimal.Decimal
+		expectedErr error
 	}{
 		{
 			name:     "valid number",
@@ -412,14 +388,16 @@ func TestParseDecimal(t *testing.T) {
 			expected: decimal.NewFromInt(123),
 		},
 		{
-			name:     "invalid number",
-			input:    "tytyt",
-			expected: decimal.Zero,
+			name:        "invalid number",
+			input:       "tytyt",
+			expected:    decimal.Zero,
+			expectedErr: errNotANumber,
 		},
 		{
-			name:     "empty string",
-			input:    "",
-			expected: decimal.Zero,
+			name:        "empty string",
+			input:       "",
+			expected:    decimal.Zero,
+			expectedErr: errMissingNumber,
 		},
 		{
 			name:     "negative number",
@@ -432,9 +410,10 @@ func TestParseDecimal(t *testing.T) {
 			expected: decimal.NewFromFloat(123.45),
 		},
 		{
-			name:     "invalid decimal numbers",
-			input:    "123.45.67",
-			expected: decimal.Zero,
+			name:        "invalid decimal numbers",
+			input:       "123.45.67",
+			expected:    decimal.Zero,
+			expectedErr: errNotANumber,
 		},
 		{
 			name:     "zero",
@@ -473,8 +452,9 @@ func TestParseDecimal(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			result := parseDecimal(test.input)
+			result, err := parseNumber(test.input)
 			assert.Equal(t, test.expected, result)
+			assert.Equal(t, test.expectedErr, err)
 		})
 	}
 }
@@ -674,10 +654,7 @@ func TestProcessCustomDelimiters(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			// Reset customDelimiters before each test
-			customDelimiters = nil
-
-			result, err := processCustomDelimiters(test.input)
+			result, customDelimiters, _, err := processCustomDelimiters(test.input)
 			if test.expectedErr != "" {
 				assert.EqualError(t, err, test.expectedErr)
 			} else {
@@ -811,3 +788,192 @@ func TestAllowNegatives(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatorOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		input       string
+		expected    []decimal.Decimal
+		expectedErr string
+	}{
+		{
+			name:     "defaults accept comma and newline",
+			opts:     nil,
+			input:    "1,2\n3",
+			expected: []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2), decimal.NewFromInt(3)},
+		},
+		{
+			name:     "alternate default delimiter",
+			opts:     []Option{WithDefaultDelimiter(";")},
+			input:    "1,2;3",
+			expected: []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2), decimal.NewFromInt(3)},
+		},
+		{
+			name:     "replaced delimiter set",
+			opts:     []Option{WithDelimiters("|")},
+			input:    "1|2\n3",
+			expected: []decimal.Decimal{decimal.NewFromInt(1), decimal.Zero},
+		},
+		{
+			name:        "negatives rejected by default",
+			opts:        nil,
+			input:       "1,-2",
+			expectedErr: "invalid input: negative numbers found: -2",
+		},
+		{
+			name:     "negatives allowed",
+			opts:     []Option{WithAllowNegatives(true)},
+			input:    "1,-2",
+			expected: []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(-2)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := New(test.opts...).ValidateInput(test.input)
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, result)
+			}
+		})
+	}
+}
+
+func TestValidatorConcurrentUse(t *testing.T) {
+	validator := New()
+	inputs := map[string]int{
+		"//;\n1;2;3":     3,
+		"//[**]\n1**2":   2,
+		"1,2,3,4":        4,
+		"//[r9r]\n1r9r2": 2,
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		for input, count := range inputs {
+			wg.Add(1)
+			go func(input string, count int) {
+				defer wg.Done()
+				result, err := validator.ValidateInput(input)
+				assert.NoError(t, err)
+				assert.Len(t, result, count)
+			}(input, count)
+		}
+	}
+	wg.Wait()
+}
+
+func TestCheckNegatives(t *testing.T) {
+	numbers := []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(-2), decimal.NewFromFloat(-0.5)}
+	offsets := []int{0, 2, 5}
+
+	assert.EqualError(t, New().CheckNegatives(numbers, offsets), "invalid input: negative numbers found: -2, -0.5")
+	assert.NoError(t, New(WithAllowNegatives(true)).CheckNegatives(numbers, offsets))
+	assert.NoError(t, New().CheckNegatives([]decimal.Decimal{decimal.Zero}, []int{0}))
+}
+
+func TestStrictMode(t *testing.T) {
+	tests := []struct {
+		name            string
+		strict          bool
+		input           string
+		expected        []decimal.Decimal
+		expectedInvalid []*InvalidTokenError
+		expectedErr     string
+	}{
+		{
+			name:     "valid input",
+			strict:   true,
+			input:    "1,2",
+			expected: []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2)},
+		},
+		{
+			name:     "empty input is still zero",
+			strict:   true,
+			input:    "",
+			expected: []decimal.Decimal{decimal.Zero},
+		},
+		{
+			name:        "invalid tokens rejected",
+			strict:      true,
+			input:       "1,abc,,1.2.3",
+			expectedErr: `invalid input: invalid tokens found: "abc" at index 1 (offset 2): not a number; "" at index 2 (offset 6): missing number; "1.2.3" at index 3 (offset 7): not a number`,
+		},
+		{
+			name:        "offsets include the custom delimiter header",
+			strict:      true,
+			input:       "//;\n1; x",
+			expectedErr: `invalid input: invalid tokens found: "x" at index 1 (offset 7): not a number`,
+		},
+		{
+			name:     "lenient mode reports invalid tokens",
+			strict:   false,
+			input:    "1,abc,",
+			expected: []decimal.Decimal{decimal.NewFromInt(1), decimal.Zero, decimal.NewFromInt(0)},
+			expectedInvalid: []*InvalidTokenError{
+				{Token: "abc", Index: 1, Offset: 2, Reason: "not a number"},
+				{Token: "", Index: 2, Offset: 6, Reason: "missing number"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := New(WithStrict(test.strict)).Parse(test.input)
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				var tokensErr *InvalidTokensError
+				assert.ErrorAs(t, err, &tokensErr)
+				var tokenErr *InvalidTokenError
+				assert.ErrorAs(t, err, &tokenErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, result.Values)
+				assert.Equal(t, test.expectedInvalid, result.InvalidTokens)
+			}
+		})
+	}
+}
+
+func TestMaxExponent(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		input       string
+		expectedErr string
+	}{
+		{name: "within the limit", opts: []Option{WithMaxExponent(100)}, input: "1e-100,2.5e100"},
+		{name: "negative exponent", opts: []Option{WithMaxExponent(100)}, input: "1,1e-50000000", expectedErr: `invalid input: invalid tokens found: "1e-50000000" at index 1 (offset 2): exponent out of range`},
+		{name: "positive exponent", opts: []Option{WithMaxExponent(100)}, input: "1e101", expectedErr: `invalid input: invalid tokens found: "1e101" at index 0 (offset 0): exponent out of range`},
+		{name: "long decimal", opts: []Option{WithMaxExponent(2)}, input: "0.001", expectedErr: `invalid input: invalid tokens found: "0.001" at index 0 (offset 0): exponent out of range`},
+		{name: "fraction within the limit", opts: []Option{WithMaxExponent(100), WithFractions(), WithFormats(Scientific())}, input: "1/3*10^100"},
+		{name: "fraction exponent", opts: []Option{WithMaxExponent(100), WithFractions(), WithFormats(Scientific())}, input: "1/3*10^300000000", expectedErr: `invalid input: invalid tokens found: "1/3*10^300000000" at index 0 (offset 0): exponent out of range`},
+		{name: "long denominator", opts: []Option{WithMaxExponent(2), WithFractions()}, input: "1,1/100", expectedErr: `invalid input: invalid tokens found: "1/100" at index 1 (offset 2): fraction out of range`},
+		{name: "long whole part", opts: []Option{WithMaxExponent(2), WithFractions()}, input: "100 1/2", expectedErr: `invalid input: invalid tokens found: "100 1/2" at index 0 (offset 0): fraction out of range`},
+		{name: "no limit", input: "1e-500"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := New(test.opts...)
+			_, err := v.Parse(test.input)
+			_, streamErr := parseStreamAll(v, test.input)
+			if test.expectedErr == "" {
+				assert.NoError(t, err)
+				assert.NoError(t, streamErr)
+				return
+			}
+			assert.EqualError(t, err, test.expectedErr)
+			assert.EqualError(t, streamErr, test.expectedErr)
+			for _, err := range []error{err, streamErr} {
+				var tokensErr *InvalidTokensError
+				if assert.ErrorAs(t, err, &tokensErr) {
+					assert.True(t, tokensErr.Tokens[0].Rejected)
+				}
+			}
+		})
+	}
+}